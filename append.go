@@ -3,6 +3,7 @@ package gologs
 import (
 	"fmt"
 	"strconv"
+	"time"
 )
 
 func appendBool(buf []byte, name string, value bool) []byte {
@@ -14,6 +15,13 @@ func appendBool(buf []byte, name string, value bool) []byte {
 	return append(buf, []byte("false,")...)
 }
 
+func appendDuration(buf []byte, name string, value time.Duration, formatter DurationFormatter) []byte {
+	buf = appendEncodedJSONFromString(buf, name)
+	buf = append(buf, ':')
+	buf = formatter(buf, value)
+	return append(buf, ',')
+}
+
 func appendFloat(buf []byte, name string, value float64) []byte {
 	buf = appendEncodedJSONFromString(buf, name)
 	buf = append(buf, ':')
@@ -39,6 +47,13 @@ func appendString(buf []byte, name, value string) []byte {
 	return append(buf, ',')
 }
 
+func appendTime(buf []byte, name string, value time.Time, layout string) []byte {
+	buf = appendEncodedJSONFromString(buf, name)
+	buf = append(buf, ':')
+	buf = appendEncodedJSONFromTime(buf, value, layout)
+	return append(buf, ',')
+}
+
 func appendUint(buf []byte, name string, value uint64) []byte {
 	buf = appendEncodedJSONFromString(buf, name)
 	buf = append(buf, ':')
@@ -0,0 +1,146 @@
+package gologs
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// appendEncodedJSONFromTime appends the JSON encoded form of the time value,
+// formatted using the specified layout, to the provided byte slice. It only
+// allocates when the formatted time requires escaping, which cannot happen
+// for any of the layouts provided by the time package.
+func appendEncodedJSONFromTime(buf []byte, t time.Time, layout string) []byte {
+	buf = append(buf, '"')
+	start := len(buf)
+	buf = t.AppendFormat(buf, layout)
+
+	for _, b := range buf[start:] {
+		if b >= utf8.RuneSelf || special[b] <= 0 {
+			// Rare: the layout includes characters that must be escaped.
+			s := string(buf[start:])
+			return appendEncodedJSONFromString(buf[:start-1], s)
+		}
+	}
+
+	return append(buf, '"')
+}
+
+// appendEncodedJSONFromDuration appends the JSON encoded form of the Go string
+// representation of the duration, for instance "1h2m0.5s" or "1.5ms", to the
+// provided byte slice without allocating.
+func appendEncodedJSONFromDuration(buf []byte, d time.Duration) []byte {
+	// Largest duration is 2562047h47m16.854775808s, and the below logic is
+	// adapted from the standard library's time.Duration.String method,
+	// writing into an array on the stack rather than allocating a string.
+	var arr [32]byte
+	w := len(arr)
+
+	u := uint64(d)
+	neg := d < 0
+	if neg {
+		u = -u
+	}
+
+	if u < uint64(time.Second) {
+		// Special case: if duration is smaller than a second, use smaller
+		// units, like 1.2ms.
+		var prec int
+		w--
+		arr[w] = 's'
+		w--
+		switch {
+		case u == 0:
+			arr[w] = '0'
+			buf = append(buf, '"')
+			buf = append(buf, arr[w:]...)
+			return append(buf, '"')
+		case u < uint64(time.Microsecond):
+			prec = 0
+			arr[w] = 'n'
+		case u < uint64(time.Millisecond):
+			prec = 3
+			// U+00B5 'µ' micro sign == 0xC2 0xB5
+			w--
+			copy(arr[w:], "µ")
+		default:
+			prec = 6
+			arr[w] = 'm'
+		}
+		w, u = durationFrac(arr[:w], u, prec)
+		w = durationInt(arr[:w], u)
+	} else {
+		w--
+		arr[w] = 's'
+
+		w, u = durationFrac(arr[:w], u, 9)
+
+		// u is now integer seconds
+		w = durationInt(arr[:w], u%60)
+		u /= 60
+
+		// u is now integer minutes
+		if u > 0 {
+			w--
+			arr[w] = 'm'
+			w = durationInt(arr[:w], u%60)
+			u /= 60
+
+			// u is now integer hours; stop at hours because days can be
+			// different lengths.
+			if u > 0 {
+				w--
+				arr[w] = 'h'
+				w = durationInt(arr[:w], u)
+			}
+		}
+	}
+
+	if neg {
+		w--
+		arr[w] = '-'
+	}
+
+	buf = append(buf, '"')
+	buf = append(buf, arr[w:]...)
+	return append(buf, '"')
+}
+
+// durationFrac formats the fraction of v/10**prec (e.g., ".12345") into the
+// tail of buf, omitting trailing zeros. It omits the decimal point too when
+// the fraction is 0. It returns the index where the output bytes begin and
+// the value v/10**prec.
+func durationFrac(buf []byte, v uint64, prec int) (nw int, nv uint64) {
+	w := len(buf)
+	print := false
+	for i := 0; i < prec; i++ {
+		digit := v % 10
+		print = print || digit != 0
+		if print {
+			w--
+			buf[w] = byte(digit) + '0'
+		}
+		v /= 10
+	}
+	if print {
+		w--
+		buf[w] = '.'
+	}
+	return w, v
+}
+
+// durationInt formats v into the tail of buf. It returns the index where the
+// output begins.
+func durationInt(buf []byte, v uint64) int {
+	w := len(buf)
+	if v == 0 {
+		w--
+		buf[w] = '0'
+	} else {
+		for v > 0 {
+			w--
+			buf[w] = byte(v%10) + '0'
+			v /= 10
+		}
+	}
+	return w
+}
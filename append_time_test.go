@@ -0,0 +1,41 @@
+package gologs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAppendEncodedJSONFromDuration(t *testing.T) {
+	durations := []time.Duration{
+		0,
+		1,
+		999,
+		1100 * time.Nanosecond,
+		2200 * time.Microsecond,
+		-3300 * time.Millisecond,
+		4*time.Minute + 5*time.Second,
+		5*time.Hour + 6*time.Minute + 7001*time.Millisecond,
+		1<<63 - 1,
+		-1 << 63,
+	}
+
+	for _, d := range durations {
+		got := appendEncodedJSONFromDuration(nil, d)
+		want := []byte(`"` + d.String() + `"`)
+		ensureBytes(t, got, want)
+	}
+}
+
+func TestAppendEncodedJSONFromTime(t *testing.T) {
+	when := time.Date(2009, 11, 10, 23, 4, 5, 0, time.UTC)
+
+	t.Run("standard layout", func(t *testing.T) {
+		got := appendEncodedJSONFromTime([]byte("prefix:"), when, time.Kitchen)
+		ensureBytes(t, got, []byte(`prefix:"11:04PM"`))
+	})
+
+	t.Run("layout requiring escaping", func(t *testing.T) {
+		got := appendEncodedJSONFromTime([]byte("prefix:"), when, `"15"\04`)
+		ensureBytes(t, got, []byte(`prefix:"\"23\"\\04"`))
+	})
+}
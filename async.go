@@ -0,0 +1,271 @@
+package gologs
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy specifies what an AsyncWriter does with an event when its
+// queue is full.
+type OverflowPolicy uint8
+
+const (
+	// OverflowBlock blocks the logging goroutine until there is room in the
+	// queue, so no events are dropped.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropNewest drops the event being written.
+	OverflowDropNewest
+
+	// OverflowDropOldest drops the oldest event in the queue to make room
+	// for the event being written.
+	OverflowDropOldest
+
+	// OverflowDropBelow drops the event being written when its level is
+	// below the drop level of the AsyncWriter, and otherwise blocks the
+	// logging goroutine until there is room in the queue. Events without a
+	// level are never dropped.
+	OverflowDropBelow
+)
+
+// ErrAsyncWriterClosed is returned when writing to a closed AsyncWriter.
+var ErrAsyncWriterClosed = errors.New("cannot write: AsyncWriter closed")
+
+// AsyncWriter is an io.Writer that copies each event into a bounded queue,
+// and writes the queued events to the underlying io.Writer from a background
+// goroutine, so a slow underlying io.Writer does not stall the goroutines that
+// log events. What happens when the queue is full is determined by its
+// OverflowPolicy.
+//
+// Because events are written in the background, errors from the underlying
+// io.Writer are returned by the following invocation of Flush or Close. The
+// Close method must be invoked before the program exits to ensure all queued
+// events are written.
+//
+//	aw := gologs.NewAsyncWriter(os.Stderr, 1024, gologs.OverflowDropBelow).SetDropLevel(gologs.Warning)
+//	defer aw.Close()
+//	log := gologs.New(aw)
+type AsyncWriter struct {
+	w         io.Writer
+	lw        LevelWriter // lw is w when w is a LevelWriter, otherwise nil
+	policy    OverflowPolicy
+	dropLevel uint32
+	dropped   uint64
+
+	mutex    sync.Mutex
+	notEmpty sync.Cond // notEmpty is signaled when an event is queued
+	notFull  sync.Cond // notFull is signaled when an event is dequeued
+	idle     sync.Cond // idle is signaled when the queue becomes empty
+	queue    []asyncEvent
+	head     int    // head is the index of the oldest queued event
+	count    int    // count is the number of queued events
+	spare    []byte // spare is swapped with the buffer of each dequeued event
+	writing  bool   // writing is true while an event is being written
+	closed   bool
+	err      error // err is the first write error since the last Flush
+	done     chan struct{}
+}
+
+// asyncEvent is an event in the queue of an AsyncWriter. Its buffer is
+// reused for later events to avoid allocating.
+type asyncEvent struct {
+	level Level
+	buf   []byte
+}
+
+// NewAsyncWriter returns a new AsyncWriter that writes to w, queueing up to
+// capacity events, and handling a full queue according to policy. It panics
+// when capacity is not greater than zero.
+func NewAsyncWriter(w io.Writer, capacity int, policy OverflowPolicy) *AsyncWriter {
+	if capacity <= 0 {
+		panic("cannot create AsyncWriter without positive capacity")
+	}
+	aw := &AsyncWriter{
+		w:         w,
+		policy:    policy,
+		dropLevel: uint32(Warning),
+		queue:     make([]asyncEvent, capacity),
+		done:      make(chan struct{}),
+	}
+	aw.lw, _ = w.(LevelWriter)
+	aw.notEmpty.L = &aw.mutex
+	aw.notFull.L = &aw.mutex
+	aw.idle.L = &aw.mutex
+	go aw.run()
+	return aw
+}
+
+// SetDropLevel changes the level below which events are dropped when the
+// queue is full and the OverflowPolicy is OverflowDropBelow. The default drop
+// level is Warning. The change is made without blocking.
+func (aw *AsyncWriter) SetDropLevel(level Level) *AsyncWriter {
+	atomic.StoreUint32(&aw.dropLevel, uint32(level))
+	return aw
+}
+
+// Dropped returns the number of events that have been dropped because the
+// queue was full.
+func (aw *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&aw.dropped)
+}
+
+// Write queues buf as an event without a level. See WriteLevel.
+func (aw *AsyncWriter) Write(buf []byte) (int, error) {
+	return aw.WriteLevel(0, buf)
+}
+
+// WriteLevel queues a copy of buf, which holds an event at the specified
+// level, to be written to the underlying io.Writer, and returns the length of
+// buf and nil, even when the event is dropped. It returns
+// ErrAsyncWriterClosed after Close has been invoked.
+func (aw *AsyncWriter) WriteLevel(level Level, buf []byte) (int, error) {
+	aw.mutex.Lock()
+	defer aw.mutex.Unlock()
+
+	for !aw.closed && aw.count == len(aw.queue) {
+		switch aw.policy {
+		case OverflowDropNewest:
+			atomic.AddUint64(&aw.dropped, 1)
+			return len(buf), nil
+		case OverflowDropOldest:
+			aw.head = (aw.head + 1) % len(aw.queue)
+			aw.count--
+			atomic.AddUint64(&aw.dropped, 1)
+		case OverflowDropBelow:
+			if level != 0 && level < Level(atomic.LoadUint32(&aw.dropLevel)) {
+				atomic.AddUint64(&aw.dropped, 1)
+				return len(buf), nil
+			}
+			aw.notFull.Wait()
+		default:
+			aw.notFull.Wait()
+		}
+	}
+	if aw.closed {
+		return 0, ErrAsyncWriterClosed
+	}
+
+	e := &aw.queue[(aw.head+aw.count)%len(aw.queue)]
+	e.level = level
+	e.buf = append(e.buf[:0], buf...)
+	aw.count++
+	aw.notEmpty.Signal()
+	return len(buf), nil
+}
+
+// run writes queued events to the underlying io.Writer until the AsyncWriter
+// is closed and its queue is empty.
+func (aw *AsyncWriter) run() {
+	defer close(aw.done)
+
+	aw.mutex.Lock()
+	defer aw.mutex.Unlock()
+
+	for {
+		for aw.count == 0 {
+			if aw.closed {
+				return
+			}
+			aw.notEmpty.Wait()
+		}
+
+		// Dequeue the oldest event, giving its slot the spare buffer so the
+		// event may be written while other events are queued.
+		e := &aw.queue[aw.head]
+		level, buf := e.level, e.buf
+		e.buf = aw.spare
+		aw.head = (aw.head + 1) % len(aw.queue)
+		aw.count--
+		aw.writing = true
+		aw.notFull.Signal()
+		aw.mutex.Unlock()
+
+		err := aw.write(level, buf)
+
+		aw.mutex.Lock()
+		aw.writing = false
+		aw.spare = buf[:0]
+		if err != nil && aw.err == nil {
+			aw.err = err
+		}
+		if aw.count == 0 {
+			aw.idle.Broadcast()
+		}
+	}
+}
+
+// write writes a single event to the underlying io.Writer, converting a
+// panic into an error so the background goroutine continues.
+func (aw *AsyncWriter) write(level Level, buf []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("cannot write: underlying io.Writer panicked")
+		}
+	}()
+	if aw.lw != nil {
+		_, err = aw.lw.WriteLevel(level, buf)
+	} else {
+		_, err = aw.w.Write(buf)
+	}
+	return err
+}
+
+// Flush waits until every queued event has been written, then flushes the
+// underlying io.Writer when it has either a Flush or a Sync method. It returns
+// the first error from writing an event since the previous invocation of
+// Flush or Close, or the error from flushing the underlying io.Writer.
+func (aw *AsyncWriter) Flush() error {
+	aw.mutex.Lock()
+	for aw.count > 0 || aw.writing {
+		aw.idle.Wait()
+	}
+	err := aw.err
+	aw.err = nil
+	aw.mutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+	switch w := aw.w.(type) {
+	case interface{ Flush() error }:
+		return w.Flush()
+	case interface{ Sync() error }:
+		return w.Sync()
+	}
+	return nil
+}
+
+// Reopen waits until every queued event has been written, then reopens the
+// underlying io.Writer when it has a Reopen method, such as a logfile.Writer
+// whose file was rotated by another program. Goroutines writing events block
+// until the underlying io.Writer has been reopened.
+func (aw *AsyncWriter) Reopen() error {
+	aw.mutex.Lock()
+	defer aw.mutex.Unlock()
+
+	for aw.count > 0 || aw.writing {
+		aw.idle.Wait()
+	}
+	if w, ok := aw.w.(interface{ Reopen() error }); ok {
+		return w.Reopen()
+	}
+	return nil
+}
+
+// Close stops accepting events, waits until every queued event has been
+// written, and flushes the underlying io.Writer like Flush, returning any
+// error Flush would return. Goroutines blocked writing to a full queue are
+// released and receive ErrAsyncWriterClosed. It does not close the underlying
+// io.Writer. It may be invoked more than once.
+func (aw *AsyncWriter) Close() error {
+	aw.mutex.Lock()
+	aw.closed = true
+	aw.notEmpty.Broadcast()
+	aw.notFull.Broadcast()
+	aw.mutex.Unlock()
+
+	<-aw.done
+	return aw.Flush()
+}
@@ -0,0 +1,163 @@
+package gologs
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// gateWriter is a test structure that blocks every write until it is
+// released, and records the level of every event it writes.
+type gateWriter struct {
+	started chan struct{} // started is closed when the first write begins
+	release chan struct{} // release is closed to allow writes to complete
+	once    sync.Once
+	buf     bytes.Buffer
+	levels  []Level
+	err     error
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (gw *gateWriter) Write(buf []byte) (int, error) {
+	panic("gateWriter is a LevelWriter")
+}
+
+func (gw *gateWriter) WriteLevel(level Level, buf []byte) (int, error) {
+	gw.once.Do(func() { close(gw.started) })
+	<-gw.release
+	gw.levels = append(gw.levels, level)
+	gw.buf.Write(buf)
+	return len(buf), gw.err
+}
+
+// reopenRecorder is a test structure that records what was written to it
+// before each time it was reopened.
+type reopenRecorder struct {
+	buf     bytes.Buffer
+	reopens []string
+	mutex   sync.Mutex
+}
+
+func (rr *reopenRecorder) Write(p []byte) (int, error) {
+	rr.mutex.Lock()
+	defer rr.mutex.Unlock()
+	return rr.buf.Write(p)
+}
+
+func (rr *reopenRecorder) Reopen() error {
+	rr.mutex.Lock()
+	defer rr.mutex.Unlock()
+	rr.reopens = append(rr.reopens, rr.buf.String())
+	return nil
+}
+
+func TestAsyncWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  OverflowPolicy
+		want    string
+		dropped uint64
+	}{
+		{"drop newest", OverflowDropNewest, "1\n2\n3\n", 2},
+		{"drop oldest", OverflowDropOldest, "1\n4\n5\n", 2},
+		{"drop below", OverflowDropBelow, "1\n2\n3\n5\n", 1},
+	}
+
+	for _, single := range tests {
+		t.Run(single.name, func(t *testing.T) {
+			gw := newGateWriter()
+			aw := NewAsyncWriter(gw, 2, single.policy).SetDropLevel(Error)
+
+			// The first event is dequeued and blocks in the gateWriter, then
+			// the next two events fill the queue.
+			_, _ = aw.WriteLevel(Info, []byte("1\n"))
+			<-gw.started
+			_, _ = aw.WriteLevel(Info, []byte("2\n"))
+			_, _ = aw.WriteLevel(Info, []byte("3\n"))
+			_, _ = aw.WriteLevel(Info, []byte("4\n"))
+
+			// The final event blocks with the OverflowDropBelow policy until
+			// the gateWriter is released.
+			done := make(chan struct{})
+			go func() {
+				_, _ = aw.WriteLevel(Error, []byte("5\n"))
+				close(done)
+			}()
+			if single.policy != OverflowDropBelow {
+				<-done
+			}
+			close(gw.release)
+			<-done
+
+			ensureError(t, aw.Close())
+			ensureBytes(t, gw.buf.Bytes(), []byte(single.want))
+			if got, want := aw.Dropped(), single.dropped; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+		})
+	}
+
+	t.Run("block", func(t *testing.T) {
+		gw := newGateWriter()
+		aw := NewAsyncWriter(gw, 1, OverflowBlock)
+
+		_, _ = aw.WriteLevel(Info, []byte("1\n"))
+		<-gw.started
+		_, _ = aw.WriteLevel(Info, []byte("2\n"))
+
+		done := make(chan struct{})
+		go func() {
+			_, _ = aw.WriteLevel(Info, []byte("3\n"))
+			close(done)
+		}()
+		close(gw.release)
+		<-done
+
+		ensureError(t, aw.Close())
+		ensureBytes(t, gw.buf.Bytes(), []byte("1\n2\n3\n"))
+		if got, want := aw.Dropped(), uint64(0); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("logger", func(t *testing.T) {
+		gw := newGateWriter()
+		gw.err = errors.New("write-boom!")
+		close(gw.release)
+		aw := NewAsyncWriter(gw, 16, OverflowBlock)
+
+		log := New(aw).SetInfo()
+		log.Info().Msg("info")
+		log.Log().Msg("log")
+		ensureError(t, aw.Flush(), "write-boom!")
+		log.Error().Msg("error")
+		ensureError(t, aw.Close(), "write-boom!")
+		ensureError(t, aw.Close())
+
+		ensureBytes(t, gw.buf.Bytes(), []byte("{\"level\":\"info\",\"message\":\"info\"}\n{\"message\":\"log\"}\n{\"level\":\"error\",\"message\":\"error\"}\n"))
+		if got, want := gw.levels, []Level{Info, 0, Error}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		_, err := aw.Write([]byte("closed"))
+		ensureError(t, err, "AsyncWriter closed")
+	})
+	t.Run("reopen", func(t *testing.T) {
+		rr := new(reopenRecorder)
+		aw := NewAsyncWriter(rr, 16, OverflowBlock)
+		_, _ = aw.Write([]byte("1\n"))
+		_, _ = aw.Write([]byte("2\n"))
+		ensureError(t, aw.Reopen())
+		_, _ = aw.Write([]byte("3\n"))
+		ensureError(t, aw.Close())
+
+		if got, want := rr.reopens, []string{"1\n2\n"}; len(got) != len(want) || got[0] != want[0] {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+		ensureBytes(t, rr.buf.Bytes(), []byte("1\n2\n3\n"))
+	})
+}
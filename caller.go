@@ -0,0 +1,132 @@
+package gologs
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// CallerTrimmer returns the form of the pathname of a source file that is
+// written in the caller property of an event. Because it returns a portion of
+// pathname, it does not need to allocate.
+type CallerTrimmer func(pathname string) string
+
+// CallerFull returns pathname unmodified.
+func CallerFull(pathname string) string { return pathname }
+
+// CallerBase returns the final element of pathname, for instance "event.go".
+func CallerBase(pathname string) string {
+	return pathname[strings.LastIndexByte(pathname, '/')+1:]
+}
+
+// CallerShort returns the final two elements of pathname, which are the
+// directory and the name of the source file, for instance "gologs/event.go".
+// This is the default CallerTrimmer.
+func CallerShort(pathname string) string {
+	i := strings.LastIndexByte(pathname, '/')
+	if i < 0 {
+		return pathname
+	}
+	return pathname[strings.LastIndexByte(pathname[:i], '/')+1:]
+}
+
+// CallerTrimPrefix returns a CallerTrimmer that removes prefix from the
+// pathname of each source file, for instance the directory of a module.
+//
+//	log = log.With().Caller(true).
+//	    CallerTrimmer(gologs.CallerTrimPrefix("/home/builder/src/")).
+//	    Logger()
+func CallerTrimPrefix(prefix string) CallerTrimmer {
+	return func(pathname string) string {
+		return strings.TrimPrefix(pathname, prefix)
+	}
+}
+
+// caller holds how a branch annotates its events with their source location
+// and stack trace.
+type caller struct {
+	enabled      bool          // enabled adds the caller property to each event
+	skip         int           // skip is the number of additional stack frames to skip
+	trimmer      CallerTrimmer // trimmer is nil to use CallerShort
+	stackOnError bool          // stackOnError adds the stack property to events at Error and above
+}
+
+// appendCaller appends the caller property to the Event, using the location
+// of the function skip frames above the caller of appendCaller. It uses
+// runtime.Callers and runtime.FuncForPC rather than runtime.Caller, which
+// allocates.
+func (event *Event) appendCaller(skip int) {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return
+	}
+	pc := pcs[0] - 1 // the call instruction, rather than the return address
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return
+	}
+	file, line := fn.FileLine(pc)
+	if event.caller.trimmer != nil {
+		file = event.caller.trimmer(file)
+	} else {
+		file = CallerShort(file)
+	}
+	start := len(event.scratch)
+	event.scratch = append(event.scratch, `"caller":"`...)
+	event.scratch = appendEscapedJSON(event.scratch, file)
+	event.scratch = append(event.scratch, ':')
+	event.scratch = strconv.AppendInt(event.scratch, int64(line), 10)
+	event.scratch = append(event.scratch, '"', ',')
+	event.reencode(start)
+}
+
+// appendStack appends the stack property to the Event, using the stack of
+// the goroutine starting with the function skip frames above the caller of
+// appendStack. Each frame is formatted like those of runtime.Stack, as the
+// name of the function followed by a line with its location.
+func (event *Event) appendStack(skip int) {
+	var pcs [64]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(skip+2, pcs[:])])
+
+	start := len(event.scratch)
+	event.scratch = append(event.scratch, `"stack":"`...)
+	for {
+		frame, more := frames.Next()
+		event.scratch = appendEscapedJSON(event.scratch, frame.Function)
+		event.scratch = append(event.scratch, `\n\t`...)
+		event.scratch = appendEscapedJSON(event.scratch, frame.File)
+		event.scratch = append(event.scratch, ':')
+		event.scratch = strconv.AppendInt(event.scratch, int64(frame.Line), 10)
+		if !more {
+			break
+		}
+		event.scratch = append(event.scratch, `\n`...)
+	}
+	event.scratch = append(event.scratch, '"', ',')
+	event.reencode(start)
+	event.stacked = true
+}
+
+// appendEscapedJSON appends s to buf, escaped to be included inside a JSON
+// string, but without the double quotes that surround a JSON string.
+func appendEscapedJSON(buf []byte, s string) []byte {
+	start := len(buf)
+	buf = appendEncodedJSONFromString(buf, s)
+	copy(buf[start:], buf[start+1:len(buf)-1])
+	return buf[:len(buf)-2]
+}
+
+// Stack encodes the stack trace of the calling goroutine to the Event as the
+// stack property, starting with the function that invoked Stack. Each frame
+// of the stack trace is formatted as the name of its function, followed by a
+// line with its location, as they are formatted by runtime.Stack. This method
+// allocates if and only if the Event will be logged.
+//
+//	log.Warning().Stack().Msg("unexpected state")
+func (event *Event) Stack() *Event {
+	if event == nil {
+		return nil
+	}
+	event.appendStack(1)
+	return event
+}
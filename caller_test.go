@@ -0,0 +1,209 @@
+package gologs
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// nextLine returns the number of the line after the line of its caller.
+func nextLine() string {
+	_, _, line, _ := runtime.Caller(1)
+	return strconv.Itoa(line + 1)
+}
+
+// callerDirectory returns the final element of the directory of this file.
+func callerDirectory() string {
+	_, file, _, _ := runtime.Caller(0)
+	return strings.TrimSuffix(CallerShort(file), "/"+CallerBase(file))
+}
+
+// logFrom logs an Info event on behalf of its caller.
+func logFrom(log *Logger) {
+	log.Info().Msg("wrapped")
+}
+
+func TestCaller(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		New(bb).SetInfo().Info().Msg("started")
+		ensureBytes(t, bb.Bytes(), []byte("{\"level\":\"info\",\"message\":\"started\"}\n"))
+	})
+
+	t.Run("branch", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		log := New(bb).SetInfo().With().Caller(true).String("module", "server").Logger()
+		want := nextLine()
+		log.Info().Int("status", 200).Msg("done")
+		ensureBytes(t, bb.Bytes(), []byte("{\"level\":\"info\",\"module\":\"server\",\"status\":200,\"caller\":\""+callerDirectory()+"/caller_test.go:"+want+"\",\"message\":\"done\"}\n"))
+	})
+
+	t.Run("inherited and disabled", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		parent := New(bb).SetInfo().With().Caller(true).CallerTrimmer(CallerBase).Logger()
+		child := parent.With().Logger()
+		quiet := parent.With().Caller(false).Logger()
+
+		want := nextLine()
+		child.Info().Msg("")
+		quiet.Info().Msg("")
+		ensureBytes(t, bb.Bytes(), []byte(""+
+			"{\"level\":\"info\",\"caller\":\"caller_test.go:"+want+"\"}\n"+
+			"{\"level\":\"info\"}\n"))
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		log := New(bb).SetInfo().With().Caller(true).CallerSkip(1).CallerTrimmer(CallerBase).Logger()
+		want := nextLine()
+		logFrom(log)
+		ensureBytes(t, bb.Bytes(), []byte("{\"level\":\"info\",\"caller\":\"caller_test.go:"+want+"\",\"message\":\"wrapped\"}\n"))
+	})
+
+	t.Run("writer", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		w := New(bb).SetInfo().With().Caller(true).CallerTrimmer(CallerBase).Logger().NewWriter(Info)
+		want := nextLine()
+		_, _ = w.Write([]byte("line"))
+		ensureBytes(t, bb.Bytes(), []byte("{\"level\":\"info\",\"caller\":\"caller_test.go:"+want+"\",\"message\":\"line\"}\n"))
+	})
+
+	t.Run("standard library logger", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		log := New(bb).SetInfo().With().Caller(true).CallerTrimmer(CallerBase).StackOnError(true).Logger()
+		sl := log.NewStdLogWriter(Info).ParseLevels(true).Logger()
+
+		want := nextLine()
+		sl.Print("line")
+		ensureBytes(t, bb.Bytes(), []byte("{\"level\":\"info\",\"caller\":\"caller_test.go:"+want+"\",\"message\":\"line\"}\n"))
+
+		bb.Reset()
+		want = nextLine()
+		sl.Printf("[ERROR] %s", "failed")
+		var decoded struct{ Caller, Stack string }
+		if err := json.Unmarshal(bb.Bytes(), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := decoded.Caller, "caller_test.go:"+want; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if first := strings.SplitN(decoded.Stack, "\n", 3)[1]; !strings.HasSuffix(first, "/caller_test.go:"+want) {
+			t.Errorf("GOT: %v; WANT: %v", first, "caller_test.go:"+want)
+		}
+
+		// The skip applies only to events written through the StdLogWriter.
+		bb.Reset()
+		want = nextLine()
+		log.Info().Msg("direct")
+		ensureBytes(t, bb.Bytes(), []byte("{\"level\":\"info\",\"caller\":\"caller_test.go:"+want+"\",\"message\":\"direct\"}\n"))
+	})
+
+	t.Run("encoder", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		log := NewWithEncoder(bb, LogfmtEncoder{}).SetInfo().With().Caller(true).CallerTrimmer(CallerBase).Logger()
+		want := nextLine()
+		log.Info().Msg("done")
+		ensureBytes(t, bb.Bytes(), []byte("level=info caller=caller_test.go:"+want+" message=done\n"))
+	})
+
+	t.Run("allocations", func(t *testing.T) {
+		log := New(io.Discard).SetInfo().With().Caller(true).Logger()
+		emit := func() { log.Info().String("module", "server").Msg("started") }
+		if got := testing.AllocsPerRun(100, emit); got != 0 {
+			t.Errorf("GOT: %v; WANT: %v", got, 0)
+		}
+	})
+}
+
+func TestCallerTrimmers(t *testing.T) {
+	const pathname = "/home/builder/src/gologs/event.go"
+	tests := []struct {
+		name    string
+		trimmer CallerTrimmer
+		want    string
+	}{
+		{"full", CallerFull, pathname},
+		{"base", CallerBase, "event.go"},
+		{"base without directory", func(string) string { return CallerBase("event.go") }, "event.go"},
+		{"short", CallerShort, "gologs/event.go"},
+		{"short without directory", func(string) string { return CallerShort("event.go") }, "event.go"},
+		{"short relative", func(string) string { return CallerShort("gologs/event.go") }, "gologs/event.go"},
+		{"trim prefix", CallerTrimPrefix("/home/builder/src/"), "gologs/event.go"},
+		{"trim other prefix", CallerTrimPrefix("/tmp/"), pathname},
+	}
+
+	for _, single := range tests {
+		t.Run(single.name, func(t *testing.T) {
+			if got, want := single.trimmer(pathname), single.want; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestStack(t *testing.T) {
+	// stack decodes the stack property of a JSON event.
+	stack := func(t *testing.T, event []byte) string {
+		t.Helper()
+		var decoded struct{ Stack string }
+		if err := json.Unmarshal(event, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		return decoded.Stack
+	}
+
+	t.Run("explicit", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		want := nextLine()
+		New(bb).SetInfo().Warning().Stack().Msg("unexpected")
+
+		got := stack(t, bb.Bytes())
+		if prefix := "github.com/karrick/gologs.TestStack.func2\n\t"; !strings.HasPrefix(got, prefix) {
+			t.Errorf("\nGOT:  %q\nWANT: %q\n", got, prefix)
+		}
+		if first := strings.SplitN(got, "\n", 3)[1]; !strings.HasSuffix(first, "/caller_test.go:"+want) {
+			t.Errorf("GOT: %v; WANT: %v", first, "caller_test.go:"+want)
+		}
+		if !strings.Contains(got, "\ntesting.tRunner\n\t") {
+			t.Errorf("GOT: %q; WANT: testing.tRunner frame", got)
+		}
+	})
+
+	t.Run("on error", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		log := New(bb).SetInfo().With().StackOnError(true).Logger()
+
+		log.Warning().Msg("not an error")
+		ensureBytes(t, bb.Bytes(), []byte("{\"level\":\"warning\",\"message\":\"not an error\"}\n"))
+
+		bb.Reset()
+		want := nextLine()
+		log.Error().Msg("cannot respond")
+		got := stack(t, bb.Bytes())
+		if first := strings.SplitN(got, "\n", 3)[1]; !strings.HasSuffix(first, "/caller_test.go:"+want) {
+			t.Errorf("GOT: %v; WANT: %v", first, "caller_test.go:"+want)
+		}
+
+		bb.Reset()
+		log.Error().Stack().Msg("once")
+		if got, want := bytes.Count(bb.Bytes(), []byte(`"stack":`)), 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		bb.Reset()
+		log.Error().Msg("stack of each event")
+		if got, want := bytes.Count(bb.Bytes(), []byte(`"stack":`)), 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("nil event", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		ensureError(t, New(bb).SetInfo().Debug().Stack().Msg("below level"))
+		ensureBytes(t, bb.Bytes(), nil)
+	})
+}
@@ -0,0 +1,177 @@
+package gologs
+
+import (
+	"io"
+	"os"
+)
+
+// ANSI escape sequences used by the ConsoleEncoder when Color is true.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiBlue    = "\x1b[34m"
+	ansiCyan    = "\x1b[36m"
+	ansiGray    = "\x1b[90m"
+	ansiBoldRed = "\x1b[1;31m"
+)
+
+// consoleLevelWidth is the width to which level names are padded, so the
+// messages of consecutive events are aligned.
+const consoleLevelWidth = 7
+
+// ConsoleEncoder is an Encoder that formats each event as a human readable
+// line, intended for interactive command line programs rather than for
+// consumption by other programs. Each line contains the time, when the
+// Logger has a TimeFormatter, the level, the message, and then each property
+// formatted as name=value.
+//
+//	log := gologs.NewWithEncoder(os.Stderr, &gologs.ConsoleEncoder{Color: gologs.IsTerminal(os.Stderr)})
+//	log.Warning().String("pathname", "/tmp/foo").Msg("cannot open")
+//	// Output:
+//	// WARNING cannot open pathname=/tmp/foo
+type ConsoleEncoder struct {
+	// Color causes the time, level, and property names to be highlighted
+	// using ANSI escape sequences.
+	Color bool
+}
+
+// IsTerminal returns true when w is an *os.File that refers to a terminal,
+// which is useful to decide whether a ConsoleEncoder should emit color.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Begin returns buf unchanged because console lines have no prefix.
+func (ce *ConsoleEncoder) Begin(buf []byte) []byte {
+	return buf
+}
+
+// Level appends the upper case name of the level, padded to align the
+// message that follows it. The name of the level property is not written.
+func (ce *ConsoleEncoder) Level(buf []byte, _ string, level Level) []byte {
+	name := level.String()
+	if ce.Color {
+		buf = append(buf, consoleLevelColor(level)...)
+		buf = append(buf, name...)
+		buf = append(buf, ansiReset...)
+	} else {
+		buf = append(buf, name...)
+	}
+	for i := len(name); i < consoleLevelWidth; i++ {
+		buf = append(buf, ' ')
+	}
+	return append(buf, ' ')
+}
+
+// Time re-encodes the time property as its value alone.
+func (ce *ConsoleEncoder) Time(buf []byte, start int) []byte {
+	end := len(buf)
+	_, value := splitProperty(buf[start:end])
+	if len(value) > 1 && value[0] == '"' && !containsByte(value, '\\') {
+		value = value[1 : len(value)-1]
+	}
+	if ce.Color {
+		buf = append(buf, ansiGray...)
+		buf = append(buf, value...)
+		buf = append(buf, ansiReset...)
+	} else {
+		buf = append(buf, value...)
+	}
+	buf = append(buf, ' ')
+	return replaceTail(buf, start, end)
+}
+
+// Property re-encodes the property as name=value, where string values are
+// only quoted when they would otherwise be ambiguous.
+func (ce *ConsoleEncoder) Property(buf []byte, start int) []byte {
+	end := len(buf)
+	name, value := splitProperty(buf[start:end])
+	if ce.Color {
+		buf = append(buf, ansiCyan...)
+		buf = append(buf, name...)
+		buf = append(buf, ansiReset...)
+	} else {
+		buf = append(buf, name...)
+	}
+	buf = append(buf, '=')
+	if s, ok := unquotedString(value, "="); ok {
+		buf = append(buf, s...)
+	} else {
+		buf = append(buf, value...)
+	}
+	buf = append(buf, ' ')
+	return replaceTail(buf, start, end)
+}
+
+// End inserts the message after the level, ahead of the properties, and
+// terminates the line. Trailing newlines are removed from the message, so
+// lines written to a Writer do not result in empty lines. The name of the
+// message property is not written.
+func (ce *ConsoleEncoder) End(buf []byte, fields int, _, message string) []byte {
+	for len(buf) > fields && buf[len(buf)-1] == ' ' {
+		buf = buf[:len(buf)-1]
+	}
+	for len(message) > 0 && message[len(message)-1] == '\n' {
+		message = message[:len(message)-1]
+	}
+
+	if message != "" {
+		// Grow buf by the length of the message, plus a space when the
+		// message is followed by properties, then shift the properties to
+		// make room for them.
+		end := len(buf)
+		buf = append(buf, message...)
+		if end > fields {
+			buf = append(buf, ' ')
+		}
+		copy(buf[fields+len(buf)-end:], buf[fields:end])
+		n := copy(buf[fields:], message)
+		if end > fields {
+			buf[fields+n] = ' '
+		}
+	} else {
+		for len(buf) > 0 && buf[len(buf)-1] == ' ' {
+			buf = buf[:len(buf)-1]
+		}
+	}
+
+	return append(buf, '\n')
+}
+
+// consoleLevelColor returns the ANSI escape sequence used to highlight the
+// level.
+func consoleLevelColor(level Level) string {
+	switch level {
+	case Trace, Debug:
+		return ansiGray
+	case Verbose:
+		return ansiBlue
+	case Info:
+		return ansiGreen
+	case Warning:
+		return ansiYellow
+	case Error, Fatal, Panic:
+		return ansiBoldRed
+	}
+	return ansiBold
+}
+
+// containsByte returns true when buf contains b.
+func containsByte(buf []byte, b byte) bool {
+	for _, c := range buf {
+		if c == b {
+			return true
+		}
+	}
+	return false
+}
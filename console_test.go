@@ -0,0 +1,113 @@
+package gologs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConsoleEncoder(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		call func(*Logger)
+	}{
+		{
+			"message only",
+			"WARNING some message\n",
+			func(l *Logger) { l.Warning().Msg("some message") },
+		},
+		{
+			"message and properties",
+			"ERROR   some message happy=true usage=42.3 age=42 eye-color=brown\n",
+			func(l *Logger) {
+				l.Error().Bool("happy", true).Float("usage", 42.3).Int("age", 42).String("eye-color", "brown").Msg("some message")
+			},
+		},
+		{
+			"properties without message",
+			"WARNING age=42\n",
+			func(l *Logger) { l.Warning().Int("age", 42).Msg("") },
+		},
+		{
+			"ambiguous strings are quoted",
+			"WARNING quoting empty=\"\" space=\"a b\" equals=\"a=b\" quote=\"a\\\"b\" error=\"bytes.Buffer: too large\"\n",
+			func(l *Logger) {
+				l.Warning().
+					String("empty", "").
+					String("space", "a b").
+					String("equals", "a=b").
+					String("quote", "a\"b").
+					Err(bytes.ErrTooLarge).
+					Msg("quoting")
+			},
+		},
+		{
+			"nested properties remain JSON",
+			"WARNING nested http={\"method\":\"GET\"} ids=[1,2]\n",
+			func(l *Logger) {
+				l.Warning().
+					Object("http", func(o *Object) { o.String("method", "GET") }).
+					Array("ids", func(a *Array) { a.Int(1).Int(2) }).
+					Msg("nested")
+			},
+		},
+		{
+			"time and branch",
+			"3:14PM INFO    started module=signals pid=42\n",
+			func(l *Logger) {
+				l.SetTimeFormatter(func(buf []byte) []byte {
+					return appendString(buf, "time", "3:14PM")
+				})
+				l.With().String("module", "signals").Logger().SetInfo().Info().Int("pid", 42).Msg("started")
+			},
+		},
+		{
+			"writer drops trailing newline",
+			"INFO    line 1\n",
+			func(l *Logger) { l.SetInfo().NewWriter(Info).Write([]byte("line 1\n")) },
+		},
+		{
+			"log without level",
+			"foo=bar\n",
+			func(l *Logger) { l.Log().String("foo", "bar").Msg("") },
+		},
+		{
+			"time formatter panic",
+			"panic when time formatter invoked error=boom\n",
+			func(l *Logger) {
+				l.SetTimeFormatter(func([]byte) []byte { panic(errors.New("boom")) })
+				l.Warning().Msg("should not log")
+			},
+		},
+		{
+			"duration",
+			"WARNING took=1.5ms\n",
+			func(l *Logger) {
+				l.SetDurationFormatter(DurationString).Warning().Duration("took", 1500*time.Microsecond).Msg("")
+			},
+		},
+	}
+
+	for _, single := range tests {
+		t.Run(single.name, func(t *testing.T) {
+			bb := new(bytes.Buffer)
+			single.call(NewWithEncoder(bb, &ConsoleEncoder{}))
+			ensureBytes(t, bb.Bytes(), []byte(single.want))
+		})
+	}
+
+	t.Run("color", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		NewWithEncoder(bb, &ConsoleEncoder{Color: true}).Warning().Int("age", 42).Msg("some message")
+		want := "\x1b[33mWARNING\x1b[0m some message \x1b[36mage\x1b[0m=42\n"
+		ensureBytes(t, bb.Bytes(), []byte(want))
+	})
+
+	t.Run("is terminal", func(t *testing.T) {
+		if IsTerminal(new(bytes.Buffer)) {
+			t.Errorf("GOT: %v; WANT: %v", true, false)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package gologs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// contextKey is the type of the key used to store a Logger in a
+// context.Context, preventing collisions with keys defined in other packages.
+type contextKey struct{}
+
+// fallback holds the *Logger returned by FromContext when a context.Context
+// does not contain a Logger.
+var fallback atomic.Value
+
+func init() {
+	fallback.Store(New(os.Stderr))
+}
+
+// NewContext returns a copy of ctx that carries log, which may be retrieved
+// using FromContext.
+//
+//	func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+//	    log := s.log.With().String("path", r.URL.Path).Logger()
+//	    s.handle(w, r.WithContext(gologs.NewContext(r.Context(), log)))
+//	}
+func NewContext(ctx context.Context, log *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, log)
+}
+
+// FromContext returns the Logger carried by ctx, or the fallback Logger when
+// ctx does not carry one. Unless changed by SetFallback, the fallback Logger
+// writes to standard error at the default Warning level.
+func FromContext(ctx context.Context) *Logger {
+	if log, ok := ctx.Value(contextKey{}).(*Logger); ok && log != nil {
+		return log
+	}
+	return fallback.Load().(*Logger)
+}
+
+// SetFallback changes the Logger returned by FromContext when a context does
+// not carry a Logger. It panics when log is nil.
+func SetFallback(log *Logger) {
+	if log == nil {
+		panic("cannot set nil fallback Logger")
+	}
+	fallback.Store(log)
+}
+
+// ContextFunc appends properties derived from ctx to event. It is only invoked
+// for events that will be logged, and event is never nil.
+type ContextFunc func(ctx context.Context, event *Event)
+
+// contextEntry is a registered ContextFunc, and the name of the context value
+// it adds, which is empty for callbacks registered using
+// RegisterContextFunc. Entries are compared by address when unregistered.
+type contextEntry struct {
+	name     string
+	callback ContextFunc
+}
+
+// contextFuncs holds the []*contextEntry invoked by Event.Ctx. It is replaced
+// rather than modified so Event.Ctx can read it without locking.
+var (
+	contextFuncs      atomic.Value
+	contextFuncsMutex sync.Mutex
+)
+
+// registerContextEntry registers entry, replacing any entry that has the
+// same non-empty name, and returns a function that unregisters it.
+func registerContextEntry(entry *contextEntry) func() {
+	contextFuncsMutex.Lock()
+	previous, _ := contextFuncs.Load().([]*contextEntry)
+	entries := make([]*contextEntry, 0, len(previous)+1)
+	for _, e := range previous {
+		if entry.name == "" || e.name != entry.name {
+			entries = append(entries, e)
+		}
+	}
+	contextFuncs.Store(append(entries, entry))
+	contextFuncsMutex.Unlock()
+
+	return func() {
+		contextFuncsMutex.Lock()
+		previous, _ := contextFuncs.Load().([]*contextEntry)
+		entries := make([]*contextEntry, 0, len(previous))
+		for _, e := range previous {
+			if e != entry {
+				entries = append(entries, e)
+			}
+		}
+		contextFuncs.Store(entries)
+		contextFuncsMutex.Unlock()
+	}
+}
+
+// RegisterContextFunc registers callback to be invoked by Event.Ctx, which is
+// useful for extracting values, such as trace and span identifiers, that are
+// not stored directly as context values. Callbacks are invoked in the order
+// in which they were registered. It returns a function that unregisters
+// callback.
+//
+//	gologs.RegisterContextFunc(func(ctx context.Context, event *gologs.Event) {
+//	    if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+//	        event.Stringer("trace", sc.TraceID()).Stringer("span", sc.SpanID())
+//	    }
+//	})
+func RegisterContextFunc(callback ContextFunc) func() {
+	return registerContextEntry(&contextEntry{callback: callback})
+}
+
+// RegisterContextValue registers the context value stored using key to be
+// added to events by Event.Ctx as the name property. Values of types string,
+// bool, int, int64, uint, uint64, float64, and those that implement error or
+// fmt.Stringer are encoded without formatting; values of other types are
+// formatted using fmt.Sprintf. Registering another value with the same name
+// replaces the value previously registered with that name. It returns a
+// function that unregisters the value.
+//
+//	gologs.RegisterContextValue("request", requestIDKey)
+func RegisterContextValue(name string, key interface{}) func() {
+	return registerContextEntry(&contextEntry{name: name, callback: func(ctx context.Context, event *Event) {
+		switch v := ctx.Value(key).(type) {
+		case nil:
+			// value not present
+		case string:
+			event.String(name, v)
+		case bool:
+			event.Bool(name, v)
+		case int:
+			event.Int(name, v)
+		case int64:
+			event.Int64(name, v)
+		case uint:
+			event.Uint(name, v)
+		case uint64:
+			event.Uint64(name, v)
+		case float64:
+			event.Float(name, v)
+		case error:
+			event.String(name, v.Error())
+		case fmt.Stringer:
+			event.Stringer(name, v)
+		default:
+			event.Format(name, "%v", v)
+		}
+	}})
+}
+
+// Ctx adds the values registered using RegisterContextValue and
+// RegisterContextFunc that ctx carries to the Event.
+//
+//	log.Info().Ctx(r.Context()).Msg("handling request")
+func (event *Event) Ctx(ctx context.Context) *Event {
+	if event == nil {
+		return nil
+	}
+	entries, _ := contextFuncs.Load().([]*contextEntry)
+	for _, entry := range entries {
+		entry.callback(ctx, event)
+	}
+	return event
+}
@@ -0,0 +1,75 @@
+package gologs
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+type testContextKey string
+
+func TestContext(t *testing.T) {
+	t.Run("carries logger", func(t *testing.T) {
+		log := New(new(bytes.Buffer))
+		ctx := NewContext(context.Background(), log)
+		if got, want := FromContext(ctx), log; got != want {
+			t.Errorf("GOT: %p; WANT: %p", got, want)
+		}
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		previous := FromContext(context.Background())
+		defer SetFallback(previous)
+
+		log := New(new(bytes.Buffer))
+		SetFallback(log)
+		if got, want := FromContext(context.Background()), log; got != want {
+			t.Errorf("GOT: %p; WANT: %p", got, want)
+		}
+
+		ensurePanic(t, "cannot set nil fallback Logger", func() { SetFallback(nil) })
+	})
+
+	t.Run("registered values", func(t *testing.T) {
+		t.Cleanup(RegisterContextValue("request", testContextKey("other")))
+		t.Cleanup(RegisterContextValue("request", testContextKey("request"))) // replaces other
+		t.Cleanup(RegisterContextValue("attempt", testContextKey("attempt")))
+		t.Cleanup(RegisterContextFunc(func(ctx context.Context, event *Event) {
+			if ctx.Value(testContextKey("span")) != nil {
+				event.String("span", "span-1")
+			}
+		}))
+
+		bb := new(bytes.Buffer)
+		log := New(bb)
+
+		ctx := context.WithValue(context.Background(), testContextKey("request"), "req-42")
+		log.Warning().Ctx(ctx).Msg("first")
+
+		ctx = context.WithValue(ctx, testContextKey("attempt"), 3)
+		ctx = context.WithValue(ctx, testContextKey("span"), true)
+		log.Warning().Ctx(ctx).Msg("second")
+
+		log.Debug().Ctx(ctx).Msg("not logged")
+
+		ctx = context.WithValue(ctx, testContextKey("other"), "other-1")
+		log.Warning().Ctx(ctx).Msg("third")
+
+		want := "" +
+			"{\"level\":\"warning\",\"request\":\"req-42\",\"message\":\"first\"}\n" +
+			"{\"level\":\"warning\",\"request\":\"req-42\",\"attempt\":3,\"span\":\"span-1\",\"message\":\"second\"}\n" +
+			"{\"level\":\"warning\",\"request\":\"req-42\",\"attempt\":3,\"span\":\"span-1\",\"message\":\"third\"}\n"
+		ensureBytes(t, bb.Bytes(), []byte(want))
+	})
+
+	t.Run("unregister", func(t *testing.T) {
+		unregister := RegisterContextValue("request", testContextKey("request"))
+		unregister()
+		unregister() // no effect when already unregistered
+
+		bb := new(bytes.Buffer)
+		ctx := context.WithValue(context.Background(), testContextKey("request"), "req-42")
+		New(bb).Warning().Ctx(ctx).Msg("")
+		ensureBytes(t, bb.Bytes(), []byte("{\"level\":\"warning\"}\n"))
+	})
+}
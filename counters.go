@@ -0,0 +1,190 @@
+package gologs
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// countedLevels is the number of levels for which events are counted: no
+// level, for events created by Logger.Log, and each predefined level from
+// Trace through Panic.
+const countedLevels = 9
+
+// Counters counts the events of a Logger by level, without blocking. Events
+// at custom levels are counted with the predefined level below them, or with
+// Trace when they are below Trace. A Logger only counts events after counting
+// is enabled using its SetCounting method, or using the Counting method of
+// the Intermediate that created it.
+type Counters struct {
+	emitted [countedLevels]uint64 // emitted counts events written without error
+	gated   [countedLevels]uint64 // gated counts events below the level of the Logger
+	failed  [countedLevels]uint64 // failed counts events whose write returned an error
+	enabled uint32
+}
+
+// countIndex returns the index of the counters for level.
+func countIndex(level Level) int {
+	switch {
+	case level == 0:
+		return 0
+	case level < Trace:
+		return 1
+	case level >= Panic:
+		return countedLevels - 1
+	}
+	return int(level / 10)
+}
+
+// Emitted returns the number of events at the specified level that were
+// written without error.
+func (c *Counters) Emitted(level Level) uint64 {
+	return atomic.LoadUint64(&c.emitted[countIndex(level)])
+}
+
+// Gated returns the number of events at the specified level that were not
+// created because they were below the level of the Logger.
+func (c *Counters) Gated(level Level) uint64 {
+	return atomic.LoadUint64(&c.gated[countIndex(level)])
+}
+
+// Failed returns the number of events at the specified level whose write
+// returned an error.
+func (c *Counters) Failed(level Level) uint64 {
+	return atomic.LoadUint64(&c.failed[countIndex(level)])
+}
+
+// setEnabled enables or disables counting without blocking.
+func (c *Counters) setEnabled(enabled bool) {
+	var value uint32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreUint32(&c.enabled, value)
+}
+
+// isEnabled returns true when counting is enabled, without blocking.
+func (c *Counters) isEnabled() bool {
+	return atomic.LoadUint32(&c.enabled) != 0
+}
+
+// gate counts an event at the specified level that was not created because
+// it was below the level of the Logger, when counting is enabled.
+func (c *Counters) gate(level Level) {
+	if atomic.LoadUint32(&c.enabled) != 0 {
+		atomic.AddUint64(&c.gated[countIndex(level)], 1)
+	}
+}
+
+// written counts an event at the specified level that was written, when
+// counting is enabled.
+func (c *Counters) written(level Level, err error) {
+	if atomic.LoadUint32(&c.enabled) != 0 {
+		if err != nil {
+			atomic.AddUint64(&c.failed[countIndex(level)], 1)
+		} else {
+			atomic.AddUint64(&c.emitted[countIndex(level)], 1)
+		}
+	}
+}
+
+// countedLevelLabel returns the label of the level counted at index i.
+func countedLevelLabel(i int) string {
+	if i == 0 {
+		return "none"
+	}
+	return Level(i * 10).label()
+}
+
+// WriteMetrics writes the counters of each branch in the Registry that counts
+// its events to w, using the Prometheus text exposition format. Each counter
+// is labeled by the name of its branch and by level.
+//
+//	gologs_events_emitted_total{branch="server",level="error"} 3
+func (r *Registry) WriteMetrics(w io.Writer) error {
+	r.mutex.Lock()
+	names := make([]string, 0, len(r.branches))
+	loggers := make(map[string]*Logger, len(r.branches))
+	for name, b := range r.branches {
+		if b.log.counters.isEnabled() {
+			names = append(names, name)
+			loggers[name] = b.log
+		}
+	}
+	r.mutex.Unlock()
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	metrics := []struct {
+		name     string
+		help     string
+		counters func(*Counters) *[countedLevels]uint64
+	}{
+		{"gologs_events_emitted_total", "Number of events written without error.", func(c *Counters) *[countedLevels]uint64 { return &c.emitted }},
+		{"gologs_events_gated_total", "Number of events not created because they were below the level of their branch.", func(c *Counters) *[countedLevels]uint64 { return &c.gated }},
+		{"gologs_events_failed_total", "Number of events whose write returned an error.", func(c *Counters) *[countedLevels]uint64 { return &c.failed }},
+	}
+	for _, metric := range metrics {
+		writeMetricHeader(bw, metric.name, metric.help)
+		for _, name := range names {
+			counters := metric.counters(&loggers[name].counters)
+			for i := range counters {
+				bw.WriteString(metric.name)
+				bw.WriteString(`{branch="`)
+				bw.WriteString(escapeLabel(name))
+				bw.WriteString(`",level="`)
+				bw.WriteString(countedLevelLabel(i))
+				bw.WriteString(`"} `)
+				bw.WriteString(strconv.FormatUint(atomic.LoadUint64(&counters[i]), 10))
+				bw.WriteByte('\n')
+			}
+		}
+	}
+
+	const sampled = "gologs_events_sampled_total"
+	writeMetricHeader(bw, sampled, "Number of events not logged because the Sampler of their branch did not allow them.")
+	for _, name := range names {
+		bw.WriteString(sampled)
+		bw.WriteString(`{branch="`)
+		bw.WriteString(escapeLabel(name))
+		bw.WriteString(`"} `)
+		bw.WriteString(strconv.FormatUint(loggers[name].Dropped(), 10))
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// MetricsHandler returns an http.Handler that responds to every request with
+// the counters of the Registry, as written by WriteMetrics.
+//
+//	http.Handle("/metrics", registry.MetricsHandler())
+func (r *Registry) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WriteMetrics(w)
+	})
+}
+
+// writeMetricHeader writes the HELP and TYPE lines of a counter.
+func writeMetricHeader(bw *bufio.Writer, name, help string) {
+	bw.WriteString("# HELP ")
+	bw.WriteString(name)
+	bw.WriteByte(' ')
+	bw.WriteString(help)
+	bw.WriteString("\n# TYPE ")
+	bw.WriteString(name)
+	bw.WriteString(" counter\n")
+}
+
+// labelEscaper escapes the characters that may not appear in a Prometheus
+// label value.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel returns s escaped for use as a Prometheus label value.
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
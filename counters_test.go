@@ -0,0 +1,140 @@
+package gologs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCounters(t *testing.T) {
+	t.Run("counts", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		log := New(bb).SetInfo().SetCounting(true)
+
+		log.Debug().Msg("gated")
+		log.Info().Msg("emitted")
+		log.Warning().Msg("emitted")
+		log.Error().Msg("emitted")
+		log.Log().Msg("emitted")
+		log.NewWriter(Verbose).Write([]byte("gated"))
+		log.WithLevel(Level(45)).Msg("counted as info")
+
+		c := log.Counters()
+		for i, single := range []struct {
+			level   Level
+			emitted uint64
+			gated   uint64
+		}{
+			{0, 1, 0},
+			{Debug, 0, 1},
+			{Verbose, 0, 1},
+			{Info, 2, 0},
+			{Warning, 1, 0},
+			{Error, 1, 0},
+		} {
+			if got, want := c.Emitted(single.level), single.emitted; got != want {
+				t.Errorf("Case: %d; GOT: %v; WANT: %v", i, got, want)
+			}
+			if got, want := c.Gated(single.level), single.gated; got != want {
+				t.Errorf("Case: %d; GOT: %v; WANT: %v", i, got, want)
+			}
+		}
+	})
+
+	t.Run("failed writes", func(t *testing.T) {
+		gw := newGateWriter()
+		close(gw.release)
+		gw.err = errors.New("disk full")
+		log := New(gw).SetCounting(true)
+
+		ensureError(t, log.Error().Msg("cannot save"), "disk full")
+		if got, want := log.Counters().Failed(Error), uint64(1); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := log.Counters().Emitted(Error), uint64(0); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("branches", func(t *testing.T) {
+		parent := New(io.Discard).SetInfo()
+		child := parent.With().Counting(true).Logger()
+		parent.SetCounting(true)
+		grandchild := child.With().Logger()
+		uncounted := parent.With().Counting(false).Logger()
+
+		parent.Info().Msg("")
+		child.Info().Msg("")
+		child.Info().Msg("")
+		grandchild.Debug().Msg("")
+		uncounted.Info().Msg("")
+
+		for i, single := range []struct {
+			c       *Counters
+			emitted uint64
+			gated   uint64
+		}{
+			{parent.Counters(), 1, 0},
+			{child.Counters(), 2, 0},
+			{grandchild.Counters(), 0, 1},
+			{uncounted.Counters(), 0, 0},
+		} {
+			if got, want := single.c.Emitted(Info), single.emitted; got != want {
+				t.Errorf("Logger: %d; GOT: %v; WANT: %v", i, got, want)
+			}
+			if got, want := single.c.Gated(Debug), single.gated; got != want {
+				t.Errorf("Logger: %d; GOT: %v; WANT: %v", i, got, want)
+			}
+		}
+	})
+
+	t.Run("allocations", func(t *testing.T) {
+		log := New(io.Discard).SetInfo().SetCounting(true)
+		emit := func() {
+			log.Debug().Msg("gated")
+			log.Info().String("module", "server").Msg("started")
+		}
+		if got := testing.AllocsPerRun(100, emit); got != 0 {
+			t.Errorf("GOT: %v; WANT: %v", got, 0)
+		}
+	})
+}
+
+func TestRegistryMetrics(t *testing.T) {
+	root := New(io.Discard).SetInfo()
+	server := root.With().Counting(true).Logger()
+	r := NewRegistry()
+	ensureError(t, r.Register(`server "main"`, server))
+	ensureError(t, r.Register("database", root.With().Logger())) // not counted
+
+	server.Debug().Msg("")
+	server.Warning().Msg("")
+	server.Warning().Msg("")
+
+	rec := httptest.NewRecorder()
+	r.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if got, want := rec.Header().Get("Content-Type"), "text/plain; version=0.0.4; charset=utf-8"; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE gologs_events_emitted_total counter\n",
+		`gologs_events_emitted_total{branch="server \"main\"",level="none"} 0` + "\n",
+		`gologs_events_emitted_total{branch="server \"main\"",level="warning"} 2` + "\n",
+		`gologs_events_gated_total{branch="server \"main\"",level="debug"} 1` + "\n",
+		`gologs_events_failed_total{branch="server \"main\"",level="panic"} 0` + "\n",
+		`gologs_events_sampled_total{branch="server \"main\""} 0` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("\nGOT:  %q\nWANT: %q\n", body, want)
+		}
+	}
+	if strings.Contains(body, "database") {
+		t.Errorf("GOT: %q; WANT: no database branch", body)
+	}
+}
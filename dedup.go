@@ -0,0 +1,275 @@
+package gologs
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DedupWriter is a LevelWriter that collapses bursts of identical consecutive
+// events, which are common when a program retries a failing operation in a
+// tight loop. The first event of a burst is written to the underlying
+// io.Writer, and the identical events that follow it within the window of the
+// DedupWriter are suppressed. When the burst ends, either because a different
+// event is written or because the window expires, a single summary event is
+// written: a copy of the final suppressed event with the number of suppressed
+// events, and the times the first and the last of them were written.
+//
+// Events are identical when they have the same level and the same bytes,
+// ignoring the leading time property of JSON and logfmt events. See
+// SetTimeKey. Properties
+// are appended to the summary of a JSON event as the repeated, first, and
+// last properties, and to the summary of any other event as name=value
+// pairs.
+//
+//	dw := gologs.NewDedupWriter(os.Stderr, time.Minute)
+//	defer dw.Close()
+//	log := gologs.New(dw)
+//	// Output after 1000 identical warnings:
+//	// {"level":"warning","message":"cannot connect"}
+//	// {"level":"warning","message":"cannot connect","repeated":999,"first":"2022-08-06T19:14:04.1Z","last":"2022-08-06T19:14:09.7Z"}
+type DedupWriter struct {
+	w      io.Writer
+	lw     LevelWriter // lw is w when w is a LevelWriter, otherwise nil
+	window time.Duration
+	now    func() time.Time
+
+	// jsonTime and logfmtTime are how JSON and logfmt events begin when
+	// their first property is the time property.
+	jsonTime   []byte
+	logfmtTime []byte
+
+	mutex      sync.Mutex
+	level      Level
+	event      []byte    // event is the most recent event written or suppressed
+	key        int       // key is the offset in event of the bytes compared with later events
+	expires    time.Time // expires is when the burst stops being collapsed
+	repeated   int       // repeated is the number of suppressed events
+	first      time.Time
+	last       time.Time
+	timer      *time.Timer
+	generation uint64 // generation identifies the burst for which timer was started
+	scratch    []byte // scratch is where summary events are built
+}
+
+// NewDedupWriter returns a new DedupWriter that writes to w, collapsing
+// identical consecutive events for up to window after the first of them. It
+// panics when window is not greater than zero.
+func NewDedupWriter(w io.Writer, window time.Duration) *DedupWriter {
+	if window <= 0 {
+		panic("cannot create DedupWriter without positive window")
+	}
+	dw := &DedupWriter{w: w, window: window, now: time.Now}
+	dw.lw, _ = w.(LevelWriter)
+	return dw.SetTimeKey("time")
+}
+
+// SetTimeKey changes the name of the time property that is ignored when
+// events are compared, which must match the time key of the Logger writing to
+// the DedupWriter. It must be invoked before the DedupWriter is used.
+//
+//	dw := gologs.NewDedupWriter(os.Stderr, time.Minute).SetTimeKey("ts")
+//	log := gologs.New(dw).SetTimeFormatter(gologs.TimeUnix).SetTimeKey("ts")
+func (dw *DedupWriter) SetTimeKey(key string) *DedupWriter {
+	dw.jsonTime = append([]byte{'{'}, encodeKey(key)...)
+	dw.logfmtTime = append([]byte(key), '=')
+	return dw
+}
+
+// Write writes buf as an event without a level. See WriteLevel.
+func (dw *DedupWriter) Write(buf []byte) (int, error) {
+	return dw.WriteLevel(0, buf)
+}
+
+// WriteLevel writes buf, which holds an event at the specified level, to the
+// underlying io.Writer, unless it is identical to the previous event and the
+// window of the burst they belong to has not expired, in which case it is
+// suppressed. It returns the length of buf and nil when the event is
+// suppressed.
+func (dw *DedupWriter) WriteLevel(level Level, buf []byte) (int, error) {
+	dw.mutex.Lock()
+	defer dw.mutex.Unlock()
+
+	now := dw.now()
+	key := timeEnd(buf, dw.jsonTime, dw.logfmtTime)
+	if dw.event != nil && level == dw.level && now.Before(dw.expires) && bytes.Equal(buf[key:], dw.event[dw.key:]) {
+		if dw.repeated == 0 {
+			dw.first = now
+			gen := dw.generation
+			dw.timer = time.AfterFunc(dw.expires.Sub(now), func() { dw.expire(gen) })
+		}
+		dw.repeated++
+		dw.last = now
+		dw.event = append(dw.event[:0], buf...)
+		dw.key = key
+		return len(buf), nil
+	}
+
+	err := dw.summarize()
+	dw.level = level
+	dw.event = append(dw.event[:0], buf...)
+	dw.key = key
+	dw.expires = now.Add(dw.window)
+
+	n, err2 := dw.write(level, buf)
+	if err2 != nil {
+		err = err2
+	}
+	return n, err
+}
+
+// Flush writes the summary of the current burst, if any events have been
+// suppressed, then flushes the underlying io.Writer when it has either a Flush
+// or a Sync method.
+func (dw *DedupWriter) Flush() error {
+	dw.mutex.Lock()
+	err := dw.summarize()
+	dw.mutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+	switch w := dw.w.(type) {
+	case interface{ Flush() error }:
+		return w.Flush()
+	case interface{ Sync() error }:
+		return w.Sync()
+	}
+	return nil
+}
+
+// Reopen writes the summary of the current burst, if any events have been
+// suppressed, then reopens the underlying io.Writer when it has a Reopen
+// method, so the summary is written before the underlying io.Writer is
+// reopened.
+func (dw *DedupWriter) Reopen() error {
+	dw.mutex.Lock()
+	defer dw.mutex.Unlock()
+
+	err := dw.summarize()
+	if w, ok := dw.w.(interface{ Reopen() error }); ok {
+		if err2 := w.Reopen(); err2 != nil {
+			err = err2
+		}
+	}
+	return err
+}
+
+// Close writes the summary of the current burst, if any events have been
+// suppressed, and flushes the underlying io.Writer like Flush. It does not
+// close the underlying io.Writer.
+func (dw *DedupWriter) Close() error {
+	return dw.Flush()
+}
+
+// expire writes the summary of the burst identified by gen when it is still
+// the current burst. It is invoked by the timer started when the first event
+// of the burst was suppressed.
+func (dw *DedupWriter) expire(gen uint64) {
+	dw.mutex.Lock()
+	defer dw.mutex.Unlock()
+
+	if gen == dw.generation {
+		// NOTE: There is nothing to be done to report problem to caller when
+		// cannot invoke the provided io.Writer.
+		_ = dw.summarize()
+
+		// The next event starts a new burst, even when it is identical.
+		dw.expires = time.Time{}
+	}
+}
+
+// summarize writes a summary event when any events of the current burst have
+// been suppressed, and ends the burst. It must be invoked while the
+// DedupWriter is locked.
+func (dw *DedupWriter) summarize() error {
+	if dw.repeated == 0 {
+		return nil
+	}
+	dw.timer.Stop()
+	dw.timer = nil
+	dw.generation++
+
+	dw.scratch = appendSummary(dw.scratch[:0], dw.event, dw.repeated, dw.first, dw.last)
+	dw.repeated = 0
+	dw.expires = time.Time{}
+	_, err := dw.write(dw.level, dw.scratch)
+	return err
+}
+
+// write writes buf to the underlying io.Writer. It must be invoked while the
+// DedupWriter is locked.
+func (dw *DedupWriter) write(level Level, buf []byte) (int, error) {
+	if dw.lw != nil {
+		return dw.lw.WriteLevel(level, buf)
+	}
+	return dw.w.Write(buf)
+}
+
+// appendSummary appends to dst a copy of event with the number of repeated
+// events and the times of the first and last of them added to it.
+func appendSummary(dst, event []byte, repeated int, first, last time.Time) []byte {
+	const layout = time.RFC3339Nano
+	first, last = first.UTC(), last.UTC()
+
+	body := bytes.TrimRight(event, "\n")
+	if len(body) > 0 && body[len(body)-1] == '}' {
+		dst = append(dst, body[:len(body)-1]...)
+		if len(body) > 1 && body[len(body)-2] != '{' {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, `"repeated":`...)
+		dst = strconv.AppendInt(dst, int64(repeated), 10)
+		dst = append(dst, `,"first":"`...)
+		dst = first.AppendFormat(dst, layout)
+		dst = append(dst, `","last":"`...)
+		dst = last.AppendFormat(dst, layout)
+		return append(dst, "\"}\n"...)
+	}
+
+	dst = append(dst, body...)
+	dst = append(dst, " repeated="...)
+	dst = strconv.AppendInt(dst, int64(repeated), 10)
+	dst = append(dst, " first="...)
+	dst = first.AppendFormat(dst, layout)
+	dst = append(dst, " last="...)
+	dst = last.AppendFormat(dst, layout)
+	return append(dst, '\n')
+}
+
+// timeEnd returns the offset in buf of the first byte following its leading
+// time property, when buf is a JSON event that begins with jsonTime, or a
+// logfmt event that begins with logfmtTime, and otherwise returns zero.
+func timeEnd(buf, jsonTime, logfmtTime []byte) int {
+	var i int
+	var separator byte
+	switch {
+	case bytes.HasPrefix(buf, jsonTime):
+		i, separator = len(jsonTime), ','
+	case bytes.HasPrefix(buf, logfmtTime):
+		i, separator = len(logfmtTime), ' '
+	default:
+		return 0
+	}
+
+	if i < len(buf) && buf[i] == '"' {
+		// Skip past the closing quote, which is the first double quote not
+		// escaped by a backslash.
+		for i++; i < len(buf); i++ {
+			if buf[i] == '\\' {
+				i++
+			} else if buf[i] == '"' {
+				i++
+				break
+			}
+		}
+	}
+	for ; i < len(buf); i++ {
+		if buf[i] == separator {
+			return i + 1
+		}
+	}
+	return 0
+}
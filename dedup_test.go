@@ -0,0 +1,159 @@
+package gologs
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedBuffer is a test structure that may be read while it is written by
+// another goroutine.
+type lockedBuffer struct {
+	buf   bytes.Buffer
+	mutex sync.Mutex
+}
+
+func (lb *lockedBuffer) Write(p []byte) (int, error) {
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+	return lb.buf.Write(p)
+}
+
+func (lb *lockedBuffer) String() string {
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+	return lb.buf.String()
+}
+
+// counterTime is a TimeFormatter that appends a different time to each event.
+func counterTime() TimeFormatter {
+	var i int
+	return func(buf []byte) []byte {
+		i++
+		buf = append(buf, `"time":`...)
+		buf = strconv.AppendInt(buf, int64(i), 10)
+		return append(buf, ',')
+	}
+}
+
+func TestDedupWriter(t *testing.T) {
+	t.Run("burst ends", func(t *testing.T) {
+		fc := newFakeClock()
+		bb := new(bytes.Buffer)
+		dw := NewDedupWriter(bb, time.Minute)
+		dw.now = fc.now
+		log := New(dw).SetInfo().SetTimeFormatter(counterTime())
+
+		for i := 0; i < 4; i++ {
+			log.Warning().String("dependency", "database").Msg("cannot connect")
+			fc.advance(time.Second)
+		}
+		log.Warning().String("dependency", "cache").Msg("cannot connect")
+		log.Info().String("dependency", "cache").Msg("cannot connect")
+		ensureError(t, dw.Close())
+
+		ensureBytes(t, bb.Bytes(), []byte(""+
+			"{\"time\":1,\"level\":\"warning\",\"dependency\":\"database\",\"message\":\"cannot connect\"}\n"+
+			"{\"time\":4,\"level\":\"warning\",\"dependency\":\"database\",\"message\":\"cannot connect\",\"repeated\":3,\"first\":\"2022-08-06T15:14:05Z\",\"last\":\"2022-08-06T15:14:07Z\"}\n"+
+			"{\"time\":5,\"level\":\"warning\",\"dependency\":\"cache\",\"message\":\"cannot connect\"}\n"+
+			"{\"time\":6,\"level\":\"info\",\"dependency\":\"cache\",\"message\":\"cannot connect\"}\n"))
+	})
+
+	t.Run("window ends", func(t *testing.T) {
+		fc := newFakeClock()
+		bb := new(bytes.Buffer)
+		dw := NewDedupWriter(bb, time.Minute)
+		dw.now = fc.now
+		log := NewWithEncoder(dw, LogfmtEncoder{}).SetInfo()
+
+		log.Info().Msg("retrying")
+		fc.advance(30 * time.Second)
+		log.Info().Msg("retrying")
+		fc.advance(30 * time.Second)
+		log.Info().Msg("retrying") // begins the next burst
+		log.Info().Msg("retrying")
+		ensureError(t, dw.Flush())
+
+		ensureBytes(t, bb.Bytes(), []byte(""+
+			"level=info message=retrying\n"+
+			"level=info message=retrying repeated=1 first=2022-08-06T15:14:34Z last=2022-08-06T15:14:34Z\n"+
+			"level=info message=retrying\n"+
+			"level=info message=retrying repeated=1 first=2022-08-06T15:15:04Z last=2022-08-06T15:15:04Z\n"))
+	})
+
+	t.Run("timer writes summary", func(t *testing.T) {
+		lb := new(lockedBuffer)
+		dw := NewDedupWriter(lb, 10*time.Millisecond)
+		log := New(dw).SetInfo()
+
+		log.Error().Msg("cannot write")
+		log.Error().Msg("cannot write")
+
+		deadline := time.Now().Add(5 * time.Second)
+		for strings.Count(lb.String(), "\n") < 2 {
+			if time.Now().After(deadline) {
+				t.Fatalf("GOT: %q; WANT: summary", lb.String())
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if got, want := lb.String(), "{\"level\":\"error\",\"message\":\"cannot write\",\"repeated\":1,\"first\":\""; !strings.Contains(got, want) {
+			t.Errorf("\nGOT:  %q\nWANT: %q\n", got, want)
+		}
+
+		// Once the window expires, an identical event is written again.
+		log.Error().Msg("cannot write")
+		if got, want := strings.Count(lb.String(), "\n"), 3; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureError(t, dw.Close())
+	})
+
+	t.Run("reopen", func(t *testing.T) {
+		fc := newFakeClock()
+		rr := new(reopenRecorder)
+		dw := NewDedupWriter(rr, time.Minute)
+		dw.now = fc.now
+		log := New(dw).SetInfo()
+
+		log.Warning().Msg("cannot connect")
+		log.Warning().Msg("cannot connect")
+		ensureError(t, dw.Reopen())
+		log.Warning().Msg("cannot connect")
+		ensureError(t, dw.Close())
+
+		want := "" +
+			"{\"level\":\"warning\",\"message\":\"cannot connect\"}\n" +
+			"{\"level\":\"warning\",\"message\":\"cannot connect\",\"repeated\":1,\"first\":\"2022-08-06T15:14:04Z\",\"last\":\"2022-08-06T15:14:04Z\"}\n"
+		if got := rr.reopens; len(got) != 1 || got[0] != want {
+			t.Errorf("GOT: %q; WANT: %q", got, []string{want})
+		}
+		// The burst ended when the DedupWriter was reopened.
+		ensureBytes(t, rr.buf.Bytes(), []byte(want+"{\"level\":\"warning\",\"message\":\"cannot connect\"}\n"))
+	})
+}
+
+func TestTimeEnd(t *testing.T) {
+	tests := []struct {
+		name  string
+		event string
+		want  string
+	}{
+		{"json other key", `{"ts":1643776764,"level":"info"}`, `{"ts":1643776764,"level":"info"}`},
+		{"json without time", `{"level":"info"}`, `{"level":"info"}`},
+		{"json number", `{"time":1643776764,"level":"info"}`, `"level":"info"}`},
+		{"json string", `{"time":"3:14PM \"x,y\"","level":"info"}`, `"level":"info"}`},
+		{"logfmt", "time=1643776764 level=info\n", "level=info\n"},
+		{"logfmt quoted", "time=\"Aug 6 15:14\" level=info\n", "level=info\n"},
+		{"console", "3:14PM INFO    started\n", "3:14PM INFO    started\n"},
+	}
+
+	for _, single := range tests {
+		t.Run(single.name, func(t *testing.T) {
+			buf := []byte(single.event)
+			ensureBytes(t, buf[timeEnd(buf, []byte(`{"time":`), []byte("time=")):], []byte(single.want))
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package gologs
+
+import (
+	"strconv"
+	"time"
+)
+
+// DurationFormatter appends the JSON encoded form of a time.Duration value to
+// buf. A Logger uses its DurationFormatter for every Duration property added
+// to its events and to the branches derived from it.
+type DurationFormatter func([]byte, time.Duration) []byte
+
+// DurationNanoseconds appends the duration to buf as a JSON number of
+// nanoseconds. This is the default DurationFormatter for a Logger.
+func DurationNanoseconds(buf []byte, d time.Duration) []byte {
+	return strconv.AppendInt(buf, int64(d), 10)
+}
+
+// DurationSeconds appends the duration to buf as a JSON floating point number
+// of seconds.
+func DurationSeconds(buf []byte, d time.Duration) []byte {
+	return appendEncodedJSONFromFloat(buf, d.Seconds())
+}
+
+// DurationString appends the duration to buf as a JSON string in the same
+// form returned by its String method, for instance "1m30s", without
+// allocating.
+func DurationString(buf []byte, d time.Duration) []byte {
+	return appendEncodedJSONFromDuration(buf, d)
+}
@@ -0,0 +1,106 @@
+package gologs
+
+// Encoder converts log events from the JSON form in which they are built into
+// another output form. A Logger without an Encoder writes JSON events.
+//
+// Every property is built as a JSON encoded name and value followed by a
+// comma, for instance `"status":200,`, and the Encoder is immediately given
+// the chance to re-encode that property in place. This means branch
+// properties are re-encoded only once when the branch is created, and the
+// properties of each event are re-encoded as they are added to the event,
+// rather than after the event is complete. An Encoder is shared by every
+// branch of a Logger, and by every Writer created from them, and must not
+// keep any state of its own between method invocations.
+//
+// Every method appends to or re-encodes part of buf, and returns the
+// resulting byte slice. No method may modify buf before the specified start
+// offset.
+type Encoder interface {
+	// Begin appends whatever must precede the first property of every event
+	// to buf. It is only invoked once when a Logger or Writer is created, and
+	// its result is reused for every event.
+	Begin(buf []byte) []byte
+
+	// Level appends the level of an event to buf, using name as the name
+	// of the level property when the Encoder writes one. It is not invoked
+	// for events created by Logger.Log, which have no level.
+	Level(buf []byte, name string, level Level) []byte
+
+	// Time re-encodes the JSON property appended to buf after start by the
+	// Logger's TimeFormatter.
+	Time(buf []byte, start int) []byte
+
+	// Property re-encodes the JSON property appended to buf after start. The
+	// property value may be a JSON string, number, literal, object, or array.
+	Property(buf []byte, start int) []byte
+
+	// End appends the message, which may be empty, along with whatever must
+	// follow the final property of every event to buf, using name as the
+	// name of the message property when the Encoder writes one. The fields
+	// argument is the offset in buf of the first byte following the time and
+	// the level of the event.
+	End(buf []byte, fields int, name, message string) []byte
+}
+
+// newScratch returns a new byte slice to be used for building events that
+// already contains the bytes that begin every event.
+func newScratch(encoder Encoder) []byte {
+	if encoder == nil {
+		buf := make([]byte, 1, 2048)
+		buf[0] = '{'
+		return buf
+	}
+	return encoder.Begin(make([]byte, 0, 2048))
+}
+
+// splitProperty returns the raw JSON encoded name and value of the JSON
+// property that spans the provided byte slice, which must be formatted just
+// as it was appended, for instance `"status":200,`. The returned name does
+// not include its enclosing double quotes, but may include escape sequences.
+func splitProperty(property []byte) (name, value []byte) {
+	// Skip past the opening quote, then find the closing quote, which is the
+	// first double quote not escaped by a backslash.
+	for i := 1; i < len(property); i++ {
+		switch property[i] {
+		case '\\':
+			i++ // skip escaped character
+		case '"':
+			// Value begins after the colon, and ends before the trailing
+			// comma.
+			return property[1:i], property[i+2 : len(property)-1]
+		}
+	}
+	return nil, nil
+}
+
+// unquotedString returns the contents of the JSON string value and true when
+// the value is a JSON string that can be emitted without its enclosing
+// double quotes, because it is not empty and contains no whitespace, no
+// escape sequences, and none of the characters in special. Otherwise it
+// returns nil and false.
+func unquotedString(value []byte, special string) ([]byte, bool) {
+	if len(value) < 3 || value[0] != '"' {
+		return nil, false
+	}
+	value = value[1 : len(value)-1]
+	for _, b := range value {
+		if b <= ' ' || b == '\\' || b == '"' {
+			return nil, false
+		}
+		for i := 0; i < len(special); i++ {
+			if b == special[i] {
+				return nil, false
+			}
+		}
+	}
+	return value, true
+}
+
+// replaceTail replaces the bytes in buf from start to end with the bytes that
+// were appended to buf after end, returning the resulting byte slice. This
+// allows an Encoder to append its re-encoded form of a property while reading
+// the original property, and then move it into place without allocating.
+func replaceTail(buf []byte, start, end int) []byte {
+	n := copy(buf[start:], buf[end:])
+	return buf[:start+n]
+}
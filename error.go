@@ -0,0 +1,99 @@
+package gologs
+
+// ErrorFielder is implemented by errors that describe themselves using
+// properties in addition to their message. When an error that implements
+// ErrorFielder is logged, or is found in the chain of errors wrapped by a
+// logged error, the properties it adds to the provided Object are included
+// with its message.
+//
+//	type PathError struct {
+//	    Op, Path string
+//	    Err      error
+//	}
+//
+//	func (e *PathError) ErrorFields(o *gologs.Object) {
+//	    o.String("op", e.Op).String("path", e.Path)
+//	}
+type ErrorFielder interface {
+	ErrorFields(o *Object)
+}
+
+// maxErrorChain is the maximum number of errors included in the chain of an
+// error, which guards against errors whose chains are cyclic.
+const maxErrorChain = 32
+
+// hasErrorChain returns true when err wraps at least one other error, or
+// implements ErrorFielder.
+func hasErrorChain(err error) bool {
+	switch e := err.(type) {
+	case ErrorFielder:
+		return true
+	case interface{ Unwrap() error }:
+		return e.Unwrap() != nil
+	case interface{ Unwrap() []error }:
+		for _, wrapped := range e.Unwrap() {
+			if wrapped != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// appendError appends the name property with the message of err to buf, or a
+// JSON null when err is nil. It does not append the chain of err.
+func appendError(buf []byte, name string, err error) []byte {
+	if err == nil {
+		buf = appendEncodedJSONFromString(buf, name)
+		return append(buf, ":null,"...)
+	}
+	return appendString(buf, name, err.Error())
+}
+
+// appendErrorChain appends to buf a property named by name followed by the
+// word "Chain", whose value is a JSON array of err followed by every error it
+// wraps, in depth first order. Each error is encoded as a JSON string holding
+// its message, unless it implements ErrorFielder, in which case it is encoded
+// as a JSON object holding its message and its properties.
+func appendErrorChain(buf []byte, name string, err error, o *Object) []byte {
+	buf = appendEncodedJSONFromString(buf, name)
+	buf = append(buf[:len(buf)-1], `Chain":[`...) // replace closing quote
+	var count int
+	buf = appendErrorCauses(buf, err, o, &count)
+	return appendCloseNested(buf, ']')
+}
+
+// appendErrorCauses appends err and the errors it wraps as elements of a JSON
+// array to buf, using o to encode the properties of errors that implement
+// ErrorFielder, and stopping once count reaches maxErrorChain.
+func appendErrorCauses(buf []byte, err error, o *Object, count *int) []byte {
+	if *count == maxErrorChain {
+		return buf
+	}
+	*count++
+
+	if ef, ok := err.(ErrorFielder); ok {
+		o.buf = append(buf, '{')
+		o.buf = appendString(o.buf, "message", err.Error())
+		ef.ErrorFields(o)
+		buf = appendCloseNested(o.buf, '}')
+		o.buf = nil
+	} else {
+		buf = appendEncodedJSONFromString(buf, err.Error())
+		buf = append(buf, ',')
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if wrapped := e.Unwrap(); wrapped != nil {
+			buf = appendErrorCauses(buf, wrapped, o, count)
+		}
+	case interface{ Unwrap() []error }:
+		for _, wrapped := range e.Unwrap() {
+			if wrapped != nil {
+				buf = appendErrorCauses(buf, wrapped, o, count)
+			}
+		}
+	}
+	return buf
+}
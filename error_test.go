@@ -0,0 +1,123 @@
+package gologs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// pathError is a test error that describes itself using properties.
+type pathError struct {
+	op, path string
+	err      error
+}
+
+func (e *pathError) Error() string         { return e.op + " " + e.path + ": " + e.err.Error() }
+func (e *pathError) Unwrap() error         { return e.err }
+func (e *pathError) ErrorFields(o *Object) { o.String("op", e.op).String("path", e.path) }
+
+// multiError is a test error that wraps more than one error.
+type multiError []error
+
+func (m multiError) Error() string {
+	var messages []string
+	for _, err := range m {
+		if err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (m multiError) Unwrap() []error { return m }
+
+// cyclicError is a test error that wraps itself.
+type cyclicError struct{}
+
+func (e *cyclicError) Error() string { return "cyclic" }
+func (e *cyclicError) Unwrap() error { return e }
+
+func TestErr(t *testing.T) {
+	errDisk := errors.New("disk full")
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, `{"error":null}`},
+		{"plain", errDisk, `{"error":"disk full"}`},
+		{
+			"wrapped",
+			fmt.Errorf("cannot save: %w", errDisk),
+			`{"error":"cannot save: disk full","errorChain":["cannot save: disk full","disk full"]}`,
+		},
+		{
+			"fields",
+			&pathError{"open", "/etc/app.conf", errDisk},
+			`{"error":"open /etc/app.conf: disk full","errorChain":[{"message":"open /etc/app.conf: disk full","op":"open","path":"/etc/app.conf"},"disk full"]}`,
+		},
+		{
+			"joined",
+			fmt.Errorf("cannot close: %w", multiError{&pathError{"write", "a", errDisk}, nil, errors.New("b")}),
+			`{"error":"cannot close: write a: disk full; b","errorChain":["cannot close: write a: disk full; b","write a: disk full; b",{"message":"write a: disk full","op":"write","path":"a"},"disk full","b"]}`,
+		},
+	}
+
+	for _, single := range tests {
+		t.Run(single.name, func(t *testing.T) {
+			bb := new(bytes.Buffer)
+			New(bb).Log().Err(single.err).Msg("")
+			ensureBytes(t, bb.Bytes(), []byte(single.want+"\n"))
+		})
+	}
+
+	t.Run("cyclic", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		New(bb).Log().Err(&cyclicError{}).Msg("")
+		if got, want := strings.Count(bb.String(), `"cyclic"`), maxErrorChain+1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("encoder", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		NewWithEncoder(bb, LogfmtEncoder{}).Log().Err(fmt.Errorf("cannot save: %w", errDisk)).Msg("")
+		ensureBytes(t, bb.Bytes(), []byte(`error="cannot save: disk full" errorChain="[\"cannot save: disk full\",\"disk full\"]"`+"\n"))
+	})
+
+	t.Run("allocations", func(t *testing.T) {
+		log := New(io.Discard)
+		wrapped := fmt.Errorf("cannot save: %w", errDisk)
+		emit := func() { log.Log().Err(errDisk).Err(wrapped).Msg("") }
+		if got := testing.AllocsPerRun(100, emit); got != 0 {
+			t.Errorf("GOT: %v; WANT: %v", got, 0)
+		}
+	})
+}
+
+func TestAnErr(t *testing.T) {
+	bb := new(bytes.Buffer)
+	log := New(bb).SetInfo().SetErrorKey("err")
+	branch := log.With().String("module", "server").Logger()
+
+	log.Warning().AnErr("readError", io.ErrUnexpectedEOF).AnErr("closeError", nil).Msg("")
+	log.Warning().Err(io.EOF).Msg("")
+	branch.Warning().Err(io.EOF).Msg("")
+	branch.NewWriter(Warning).Write([]byte("line"))
+	log.Warning().AnErr("", nil).Msg("")
+
+	ensureBytes(t, bb.Bytes(), []byte(""+
+		"{\"level\":\"warning\",\"readError\":\"unexpected EOF\",\"closeError\":null}\n"+
+		"{\"level\":\"warning\",\"err\":\"EOF\"}\n"+
+		"{\"level\":\"warning\",\"module\":\"server\",\"err\":\"EOF\"}\n"+
+		"{\"level\":\"warning\",\"module\":\"server\",\"message\":\"line\"}\n"+
+		"{\"level\":\"warning\",\"\":null}\n"))
+
+	if got, want := New(io.Discard).With().Logger().event.keys.error, "error"; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+}
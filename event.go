@@ -4,90 +4,154 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 )
 
 // Event is an in progress log event being formatted before it is written upon
 // calling its Msg() method. Callers never need to create an Event
-// specifically, but rather receive an Event from calling Debug(), Verbose(),
-// Info(), Warning(), or Error() methods of Logger instance.
+// specifically, but rather receive an Event from calling Trace(), Debug(),
+// Verbose(), Info(), Warning(), or Error() methods of Logger instance.
 type Event struct {
-	scratch       []byte // scratch is where new log events are built
-	timeFormatter TimeFormatter
-	output        *output
-	mutex         sync.Mutex // mutex for scratch and timeFormatter
+	scratch           []byte // scratch is where new log events are built
+	timeFormatter     TimeFormatter
+	durationFormatter DurationFormatter
+	output            *output
+	counters          *Counters // counters are the Counters of the Logger that created the Event
+	encoder           Encoder   // encoder is nil for JSON events
+	level             Level     // level is zero for events without a level
+	prefix            int       // prefix is the length of the bytes that begin every event
+	fields            int       // fields is the offset of the first property after the level
+	terminate         termination
+	nested            Object     // nested is reused for every nested object and array
+	hooks             []Hook     // hooks are invoked by Msg, in order
+	caller            caller     // caller configures the caller and stack properties
+	keys              keys       // keys are the names of the time, level, message, and error properties
+	stacked           bool       // stacked is true after the stack property was added
+	skip              int        // skip is the number of additional stack frames to skip for this event
+	mutex             sync.Mutex // mutex for scratch, timeFormatter, durationFormatter, hooks, and keys
 }
 
+// termination specifies what happens after an Event is written.
+type termination uint8
+
+const (
+	terminateNone  termination = iota
+	terminateExit              // exit the program after writing a Fatal event
+	terminatePanic             // panic after writing a Panic event
+)
+
 func (event *Event) log(branch []byte) *Event {
 	event.mutex.Lock() // unlocked inside Event.Msg()
 	if event.timeFormatter != nil && event.formatTimePanics() {
 		return nil
 	}
+	event.level = 0
+	event.fields = len(event.scratch)
 	if len(branch) > 0 {
 		event.scratch = append(event.scratch, branch...)
 	}
 	return event
 }
 
+func (event *Event) trace(branch []byte) *Event {
+	return event.leveled(Trace, "\"level\":\"trace\",", branch)
+}
+
 func (event *Event) debug(branch []byte) *Event {
-	event.mutex.Lock() // unlocked inside Event.Msg()
-	if event.timeFormatter != nil && event.formatTimePanics() {
-		return nil
-	}
-	event.scratch = append(event.scratch, []byte("\"level\":\"debug\",")...)
-	if len(branch) > 0 {
-		event.scratch = append(event.scratch, branch...)
-	}
-	return event
+	return event.leveled(Debug, "\"level\":\"debug\",", branch)
 }
 
 func (event *Event) verbose(branch []byte) *Event {
-	event.mutex.Lock() // unlocked inside Event.Msg()
-	if event.timeFormatter != nil && event.formatTimePanics() {
-		return nil
-	}
-	event.scratch = append(event.scratch, []byte("\"level\":\"verbose\",")...)
-	if len(branch) > 0 {
-		event.scratch = append(event.scratch, branch...)
-	}
-	return event
+	return event.leveled(Verbose, "\"level\":\"verbose\",", branch)
 }
 
 func (event *Event) info(branch []byte) *Event {
-	event.mutex.Lock() // unlocked inside Event.Msg()
-	if event.timeFormatter != nil && event.formatTimePanics() {
-		return nil
-	}
-	event.scratch = append(event.scratch, []byte("\"level\":\"info\",")...)
-	if len(branch) > 0 {
-		event.scratch = append(event.scratch, branch...)
-	}
-	return event
+	return event.leveled(Info, "\"level\":\"info\",", branch)
 }
 
 func (event *Event) warning(branch []byte) *Event {
-	event.mutex.Lock() // unlocked inside Event.Msg()
-	if event.timeFormatter != nil && event.formatTimePanics() {
-		return nil
+	return event.leveled(Warning, "\"level\":\"warning\",", branch)
+}
+
+func (event *Event) error(branch []byte) *Event {
+	return event.leveled(Error, "\"level\":\"error\",", branch)
+}
+
+func (event *Event) fatal(branch []byte) *Event {
+	if event = event.leveled(Fatal, "\"level\":\"fatal\",", branch); event != nil {
+		event.terminate = terminateExit
 	}
-	event.scratch = append(event.scratch, []byte("\"level\":\"warning\",")...)
-	if len(branch) > 0 {
-		event.scratch = append(event.scratch, branch...)
+	return event
+}
+
+func (event *Event) panic(branch []byte) *Event {
+	if event = event.leveled(Panic, "\"level\":\"panic\",", branch); event != nil {
+		event.terminate = terminatePanic
 	}
 	return event
 }
 
-func (event *Event) error(branch []byte) *Event {
+// begin begins a new event at the specified level, which may be a predefined
+// level or a custom level.
+func (event *Event) begin(level Level, branch []byte) *Event {
+	switch level {
+	case Trace:
+		return event.trace(branch)
+	case Debug:
+		return event.debug(branch)
+	case Verbose:
+		return event.verbose(branch)
+	case Info:
+		return event.info(branch)
+	case Warning:
+		return event.warning(branch)
+	case Error:
+		return event.error(branch)
+	case Fatal:
+		return event.fatal(branch)
+	case Panic:
+		return event.panic(branch)
+	}
+	return event.leveled(level, level.property(), branch)
+}
+
+// leveled begins a new event at the specified level, using the provided JSON
+// level property, renamed using the level key of the Event, unless the Event
+// has an Encoder. When the TimeFormatter panics, it returns nil, unless the
+// event is a Fatal or Panic event, which is built without the time.
+func (event *Event) leveled(level Level, property string, branch []byte) *Event {
 	event.mutex.Lock() // unlocked inside Event.Msg()
 	if event.timeFormatter != nil && event.formatTimePanics() {
-		return nil
+		if level != Fatal && level != Panic {
+			return nil
+		}
+		// Fatal and Panic events must still terminate the program, so after
+		// the event describing the panic is written, begin this event again
+		// without the time.
+		event.mutex.Lock() // unlocked by the Msg method of the panic event
 	}
-	event.scratch = append(event.scratch, []byte("\"level\":\"error\",")...)
+	if event.encoder != nil {
+		event.scratch = event.encoder.Level(event.scratch, event.keys.levelName, level)
+	} else {
+		event.scratch = append(event.scratch, event.keys.level...)
+		event.scratch = append(event.scratch, property[len(defaultLevelKey):]...)
+	}
+	event.level = level
+	event.fields = len(event.scratch)
 	if len(branch) > 0 {
 		event.scratch = append(event.scratch, branch...)
 	}
 	return event
 }
 
+// reencode gives the Event's Encoder, if it has one, the chance to re-encode
+// the property that was appended to the Event after start.
+func (event *Event) reencode(start int) {
+	if event.encoder != nil {
+		event.scratch = event.encoder.Property(event.scratch, start)
+	}
+}
+
 // formatTimePanics attempts to format the time using the stored time
 // formatting callback function. When the function does not panic, it returns
 // false. When the function does panic, it returns true so the Logger method
@@ -104,15 +168,30 @@ func (event *Event) formatTimePanics() (panicked bool) {
 			default:
 				err = fmt.Errorf("%v", t)
 			}
-			event.scratch = event.scratch[:1] // erase all but prefix '{'
+			event.scratch = event.scratch[:event.prefix] // erase all but prefix
+			event.fields = event.prefix
+			event.level = 0
 			event.Err(err).Msg("panic when time formatter invoked")
 			panicked = true
 		}
 	}()
+	start := len(event.scratch)
 	event.scratch = event.timeFormatter(event.scratch)
+	event.scratch = renameProperty(event.scratch, start, defaultTimeKey, event.keys.time)
+	if event.encoder != nil && len(event.scratch) > start {
+		event.scratch = event.encoder.Time(event.scratch, start)
+	}
 	return
 }
 
+// addHook adds hook to the hooks invoked by Msg, potentially blocking until
+// any in progress log event has been written.
+func (event *Event) addHook(hook Hook) {
+	event.mutex.Lock()
+	event.hooks = appendHook(event.hooks, hook)
+	event.mutex.Unlock()
+}
+
 // setTimeFormatter updates the time formatting callback function that is
 // invoked for every log message while it is being formatted, potentially
 // blocking until any in progress log event has been written.
@@ -122,39 +201,114 @@ func (event *Event) setTimeFormatter(callback TimeFormatter) {
 	event.mutex.Unlock()
 }
 
+// setDurationFormatter updates the duration formatting callback function
+// that is invoked for every Duration property, potentially blocking until any
+// in progress log event has been written.
+func (event *Event) setDurationFormatter(callback DurationFormatter) {
+	event.mutex.Lock()
+	event.durationFormatter = callback
+	event.mutex.Unlock()
+}
+
+// Array encodes a nested JSON array property value to the Event using the
+// specified name, invoking callback to add its elements. The callback is not
+// invoked when the Event will not be logged.
+//
+//	log.Info().Array("ids", func(a *gologs.Array) { a.Int(1).Int(2) }).Msg("")
+func (event *Event) Array(name string, callback func(*Array)) *Event {
+	if event == nil {
+		return nil
+	}
+	event.nested.durationFormatter = event.durationFormatter
+	start := len(event.scratch)
+	event.scratch = appendArray(event.scratch, name, &event.nested, callback)
+	event.reencode(start)
+	return event
+}
+
 // Bool encodes a boolean property value to the Event using the specified
 // name.
 func (event *Event) Bool(name string, value bool) *Event {
 	if event == nil {
 		return nil
 	}
+	start := len(event.scratch)
 	event.scratch = appendBool(event.scratch, name, value)
+	event.reencode(start)
 	return event
 }
 
-// Err encodes a possibly nil error property value to the Event. When err is
-// nil, the error value is represented as a JSON null.
-func (event *Event) Err(err error) *Event {
+// Duration encodes a time.Duration property value to the Event using the
+// specified name. The value is formatted by the Logger's DurationFormatter,
+// which by default encodes the number of nanoseconds.
+func (event *Event) Duration(name string, value time.Duration) *Event {
 	if event == nil {
 		return nil
 	}
-	if err != nil {
-		event.scratch = append(event.scratch, []byte(`"error":`)...)
-		event.scratch = appendEncodedJSONFromString(event.scratch, err.Error())
-		event.scratch = append(event.scratch, ',')
-	} else {
-		event.scratch = append(event.scratch, []byte(`"error":null,`)...)
+	start := len(event.scratch)
+	event.scratch = appendDuration(event.scratch, name, value, event.durationFormatter)
+	event.reencode(start)
+	return event
+}
+
+// AnErr encodes a possibly nil error property value to the Event using the
+// specified name. When err is nil, the error value is represented as a JSON
+// null. When err wraps other errors, using either an Unwrap() error or an
+// Unwrap() []error method, or implements ErrorFielder, the chain of err is
+// also encoded, as a JSON array property whose name is the specified name
+// followed by "Chain".
+//
+//	log.Warning().AnErr("readError", rerr).AnErr("closeError", cerr).Msg("")
+//	// Output:
+//	// {"level":"warning","readError":"cannot read: EOF","readErrorChain":["cannot read: EOF","EOF"],"closeError":null}
+func (event *Event) AnErr(name string, err error) *Event {
+	if event == nil {
+		return nil
+	}
+	start := len(event.scratch)
+	event.scratch = appendError(event.scratch, name, err)
+	event.reencode(start)
+	if err != nil && hasErrorChain(err) {
+		event.nested.durationFormatter = event.durationFormatter
+		start = len(event.scratch)
+		event.scratch = appendErrorChain(event.scratch, name, err, &event.nested)
+		event.reencode(start)
 	}
 	return event
 }
 
+// Err encodes a possibly nil error property value to the Event using the
+// Logger's error key, which is "error" unless changed using
+// Logger.SetErrorKey. When err is nil, the error value is represented as a
+// JSON null. See AnErr.
+func (event *Event) Err(err error) *Event {
+	if event == nil {
+		return nil
+	}
+	return event.AnErr(event.keys.error, err)
+}
+
+// Fields returns the encoded properties of the Event, including the
+// properties of its branch, in the form written by the Logger, which for JSON
+// events is each property followed by a comma, for instance
+// `"module":"server","status":200,`. It is meant to be used by a Hook, and the
+// returned slice is only valid until the Hook returns.
+func (event *Event) Fields() []byte {
+	if event == nil {
+		return nil
+	}
+	return event.scratch[event.fields:]
+}
+
 // Float encodes a float64 property value to the Event using the specified
 // name.
 func (event *Event) Float(name string, value float64) *Event {
 	if event == nil {
 		return nil
 	}
+	start := len(event.scratch)
 	event.scratch = appendFloat(event.scratch, name, value)
+	event.reencode(start)
 	return event
 }
 
@@ -167,7 +321,9 @@ func (event *Event) Format(name, f string, args ...interface{}) *Event {
 	if event == nil {
 		return nil
 	}
+	start := len(event.scratch)
 	event.scratch = appendFormat(event.scratch, name, f, args...)
+	event.reencode(start)
 	return event
 }
 
@@ -176,7 +332,9 @@ func (event *Event) Int(name string, value int) *Event {
 	if event == nil {
 		return nil
 	}
+	start := len(event.scratch)
 	event.scratch = appendInt(event.scratch, name, int64(value))
+	event.reencode(start)
 	return event
 }
 
@@ -185,7 +343,9 @@ func (event *Event) Int64(name string, value int64) *Event {
 	if event == nil {
 		return nil
 	}
+	start := len(event.scratch)
 	event.scratch = appendInt(event.scratch, name, value)
+	event.reencode(start)
 	return event
 }
 
@@ -194,22 +354,63 @@ func (event *Event) Int64(name string, value int64) *Event {
 // string, which will elide inclusion of the message property in the written
 // log event. This method must be invoked to complete every Event. This method
 // returns any error from attempting to write to the Logger's io.Writer.
+//
+// When the Event was created by Logger.Fatal, after writing the Event this
+// method flushes the Logger's io.Writer, runs the exit hooks, and exits the
+// program. When the Event was created by Logger.Panic, after writing the
+// Event this method panics with the message.
 func (event *Event) Msg(s string) error {
 	if event == nil {
 		return nil
 	}
 
+	terminate := event.terminate
+	event.terminate = terminateNone
+
+	err := event.write(s)
+
+	switch terminate {
+	case terminateExit:
+		_ = event.output.Flush()
+		exit()
+	case terminatePanic:
+		panic(s)
+	}
+
+	return err
+}
+
+// write completes the Event with the specified message, writes it to the
+// Logger's io.Writer, and releases the Event for the next log event.
+func (event *Event) write(s string) error {
 	// Using defer here to prevent holding lock if underlying io.Writer
 	// panics.
 	defer func() {
 		// NOTE: There is nothing to be done to report problem to caller when
 		// cannot invoke the provided io.Writer.
-		event.scratch = event.scratch[:1] // erase all but prefix '{'
+		event.scratch = event.scratch[:event.prefix] // erase all but prefix
+		event.stacked = false
+		event.skip = 0
 		event.mutex.Unlock()
 	}()
 
-	if s != "" {
-		event.scratch = append(event.scratch, []byte(`"message":`)...)
+	// Frames to skip: write, Msg, then any configured by the branch, and any
+	// added by the code that created this event on behalf of its caller.
+	if event.caller.enabled {
+		event.appendCaller(2 + event.caller.skip + event.skip)
+	}
+	if event.caller.stackOnError && event.level >= Error && !event.stacked {
+		event.appendStack(2 + event.caller.skip + event.skip)
+	}
+
+	if len(event.hooks) > 0 && !event.runHooks(s) {
+		return nil
+	}
+
+	if event.encoder != nil {
+		event.scratch = event.encoder.End(event.scratch, event.fields, event.keys.messageName, s)
+	} else if s != "" {
+		event.scratch = append(event.scratch, event.keys.message...)
 		event.scratch = appendEncodedJSONFromString(event.scratch, s)
 		event.scratch = append(event.scratch, []byte{'}', '\n'}...)
 	} else {
@@ -217,17 +418,38 @@ func (event *Event) Msg(s string) error {
 		event.scratch = append(event.scratch, '\n')
 	}
 
-	_, err := event.output.Write(event.scratch)
+	_, err := event.output.WriteLevel(event.level, event.scratch)
+	event.counters.written(event.level, err)
 	return err
 }
 
+// Object encodes a nested JSON object property value to the Event using the
+// specified name, invoking callback to add its properties. The callback is not
+// invoked when the Event will not be logged.
+//
+//	log.Info().Object("http", func(o *gologs.Object) {
+//	    o.String("method", r.Method).Int("status", status)
+//	}).Msg("")
+func (event *Event) Object(name string, callback func(*Object)) *Event {
+	if event == nil {
+		return nil
+	}
+	event.nested.durationFormatter = event.durationFormatter
+	start := len(event.scratch)
+	event.scratch = appendObject(event.scratch, name, &event.nested, callback)
+	event.reencode(start)
+	return event
+}
+
 // String encodes a string property value to the Event using the specified
 // name.
 func (event *Event) String(name, value string) *Event {
 	if event == nil {
 		return nil
 	}
+	start := len(event.scratch)
 	event.scratch = appendString(event.scratch, name, value)
+	event.reencode(start)
 	return event
 }
 
@@ -246,7 +468,23 @@ func (event *Event) Stringer(name string, stringer interface{ String() string })
 	if event == nil {
 		return nil
 	}
+	start := len(event.scratch)
 	event.scratch = appendString(event.scratch, name, stringer.String())
+	event.reencode(start)
+	return event
+}
+
+// Time encodes a time.Time property value to the Event using the specified
+// name, formatting it with the specified layout without allocating.
+//
+//	log.Info().Time("started", started, time.RFC3339Nano).Msg("")
+func (event *Event) Time(name string, value time.Time, layout string) *Event {
+	if event == nil {
+		return nil
+	}
+	start := len(event.scratch)
+	event.scratch = appendTime(event.scratch, name, value, layout)
+	event.reencode(start)
 	return event
 }
 
@@ -255,7 +493,9 @@ func (event *Event) Uint(name string, value uint) *Event {
 	if event == nil {
 		return nil
 	}
+	start := len(event.scratch)
 	event.scratch = appendUint(event.scratch, name, uint64(value))
+	event.reencode(start)
 	return event
 }
 
@@ -265,6 +505,8 @@ func (event *Event) Uint64(name string, value uint64) *Event {
 	if event == nil {
 		return nil
 	}
+	start := len(event.scratch)
 	event.scratch = appendUint(event.scratch, name, value)
+	event.reencode(start)
 	return event
 }
@@ -13,11 +13,15 @@ func main() {
 	optDebug := flag.Bool("debug", false, "Print debug output to stderr")
 	optVerbose := flag.Bool("verbose", false, "Print verbose output to stderr")
 	optQuiet := flag.Bool("quiet", false, "Print warning and error output to stderr")
+	level := gologs.Info
+	flag.Var(&level, "level", "Print events at or above level to stderr: trace, debug, verbose, info, warning, or error")
 	flag.Parse()
 
 	// Initialize the global log variable, which will be used very much like the
-	// log standard library would be used.
-	log := gologs.New(os.Stderr)
+	// log standard library would be used. Because this is an interactive
+	// program, use human readable output, highlighted when writing to a
+	// terminal.
+	log := gologs.NewWithEncoder(os.Stderr, &gologs.ConsoleEncoder{Color: gologs.IsTerminal(os.Stderr)})
 
 	// Configure log level according to command line flags.
 	if *optDebug {
@@ -27,7 +31,7 @@ func main() {
 	} else if *optQuiet {
 		log.SetError()
 	} else {
-		log.SetInfo()
+		log.SetLevel(level)
 	}
 
 	log.SetTimeFormatter(gologs.TimeFormat(time.RFC3339))
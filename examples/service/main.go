@@ -17,13 +17,16 @@ func main() {
 	optDebug := flag.Bool("debug", false, "Print debug output to stderr")
 	optVerbose := flag.Bool("verbose", false, "Print verbose output to stderr")
 	optQuiet := flag.Bool("quiet", false, "Print warning and error output to stderr")
+	level := gologs.Info
+	flag.Var(&level, "level", "Print events at or above level to stderr: trace, debug, verbose, info, warning, or error")
 	flag.Parse()
 
 	// Create a local log variable, which will be used to create log branches
 	// for other program modules.
 	log := gologs.New(os.Stderr).With().String("version", ProgramVersion).Logger()
 
-	// Configure log level according to command line flags.
+	// Configure log level according to command line flags, which may be
+	// overridden by the GOLOGS_LEVEL environment variable.
 	if *optDebug {
 		log.SetDebug()
 	} else if *optVerbose {
@@ -31,12 +34,16 @@ func main() {
 	} else if *optQuiet {
 		log.SetError()
 	} else {
-		log.SetInfo()
+		log.SetLevel(level)
+	}
+	if err := log.SetLevelFromEnv(gologs.LevelEnv); err != nil {
+		log.Warning().Err(err).Msg("cannot configure log level")
 	}
 
 	log.Verbose().
 		Bool("debug", *optDebug).
 		Bool("verbose", *optVerbose).
+		Stringer("configuredLevel", log.Level()).
 		Msg("starting service")
 
 	log.Debug().Msg("something important to developers...")
@@ -0,0 +1,51 @@
+package gologs
+
+import (
+	"os"
+	"sync"
+)
+
+var (
+	exitMutex sync.Mutex
+	exitHooks []func()
+	exitCode  = 1
+	exitFunc  = os.Exit // exitFunc is replaced by tests
+)
+
+// RegisterExitHook registers hook to be invoked after a Fatal event has been
+// written and before the program exits. Hooks are invoked in the order in
+// which they were registered. A hook that panics does not prevent the
+// remaining hooks from being invoked, nor the program from exiting.
+//
+//	gologs.RegisterExitHook(func() { _ = db.Close() })
+func RegisterExitHook(hook func()) {
+	exitMutex.Lock()
+	exitHooks = append(exitHooks, hook)
+	exitMutex.Unlock()
+}
+
+// SetExitCode changes the code with which the program exits after a Fatal
+// event has been written. The default exit code is 1.
+func SetExitCode(code int) {
+	exitMutex.Lock()
+	exitCode = code
+	exitMutex.Unlock()
+}
+
+// exit invokes the registered exit hooks, then exits the program.
+func exit() {
+	exitMutex.Lock()
+	hooks := make([]func(), len(exitHooks))
+	copy(hooks, exitHooks)
+	code := exitCode
+	exitMutex.Unlock()
+
+	for _, hook := range hooks {
+		func() {
+			defer func() { _ = recover() }()
+			hook()
+		}()
+	}
+
+	exitFunc(code)
+}
@@ -0,0 +1,130 @@
+package gologs
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// syncBuffer is a test structure that records whether it was synced.
+type syncBuffer struct {
+	bytes.Buffer
+	synced bool
+}
+
+func (sb *syncBuffer) Sync() error {
+	sb.synced = true
+	return nil
+}
+
+func TestFatal(t *testing.T) {
+	defer func(f func(int)) { exitFunc = f }(exitFunc)
+	defer func() {
+		exitMutex.Lock()
+		exitHooks, exitCode = nil, 1
+		exitMutex.Unlock()
+	}()
+
+	var code int
+	var exited bool
+	exitFunc = func(c int) { code, exited = c, true }
+
+	var order []string
+	RegisterExitHook(func() { order = append(order, "first") })
+	RegisterExitHook(func() { panic("hook-boom!") })
+	RegisterExitHook(func() { order = append(order, "third") })
+	SetExitCode(3)
+
+	sb := new(syncBuffer)
+	log := New(sb).SetError()
+	log.Fatal().String("config", "/etc/app.conf").Msg("cannot open")
+
+	want := "{\"level\":\"fatal\",\"config\":\"/etc/app.conf\",\"message\":\"cannot open\"}\n"
+	ensureBytes(t, sb.Bytes(), []byte(want))
+
+	if !sb.synced {
+		t.Errorf("GOT: %v; WANT: %v", sb.synced, true)
+	}
+	if !exited || code != 3 {
+		t.Errorf("GOT: %v, %v; WANT: %v, %v", exited, code, true, 3)
+	}
+	if got, want := len(order), 2; got != want {
+		t.Fatalf("GOT: %v; WANT: %v", got, want)
+	}
+	if order[0] != "first" || order[1] != "third" {
+		t.Errorf("GOT: %v; WANT: %v", order, []string{"first", "third"})
+	}
+
+	// The event is released before exiting, so the Logger remains usable.
+	sb.Reset()
+	log.Error().Msg("after")
+	ensureBytes(t, sb.Bytes(), []byte("{\"level\":\"error\",\"message\":\"after\"}\n"))
+}
+
+func TestPanic(t *testing.T) {
+	bb := new(bytes.Buffer)
+	log := New(bb).SetError()
+
+	ensurePanic(t, "cannot continue", func() {
+		log.With().String("module", "worker").Logger().Panic().Int("id", 7).Msg("cannot continue")
+	})
+	ensureBytes(t, bb.Bytes(), []byte("{\"level\":\"panic\",\"module\":\"worker\",\"id\":7,\"message\":\"cannot continue\"}\n"))
+
+	// The event is released before panicking, so the Logger remains usable.
+	bb.Reset()
+	log.Error().Msg("after")
+	ensureBytes(t, bb.Bytes(), []byte("{\"level\":\"error\",\"message\":\"after\"}\n"))
+}
+
+func TestTerminateWhenTimeFormatterPanics(t *testing.T) {
+	boom := func([]byte) []byte { panic("time-formatter-boom!") }
+	want := "" +
+		"{\"error\":\"time-formatter-boom!\",\"message\":\"panic when time formatter invoked\"}\n" +
+		"{\"level\":\"%s\",\"id\":7,\"message\":\"cannot continue\"}\n"
+
+	t.Run("fatal", func(t *testing.T) {
+		defer func(f func(int)) { exitFunc = f }(exitFunc)
+		var exited bool
+		exitFunc = func(int) { exited = true }
+
+		bb := new(bytes.Buffer)
+		New(bb).SetTimeFormatter(boom).Fatal().Int("id", 7).Msg("cannot continue")
+		if !exited {
+			t.Errorf("GOT: %v; WANT: %v", exited, true)
+		}
+		ensureBytes(t, bb.Bytes(), []byte(fmt.Sprintf(want, "fatal")))
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		log := New(bb).SetTimeFormatter(boom)
+		ensurePanic(t, "cannot continue", func() {
+			log.Panic().Int("id", 7).Msg("cannot continue")
+		})
+		ensureBytes(t, bb.Bytes(), []byte(fmt.Sprintf(want, "panic")))
+
+		// The Logger remains usable.
+		bb.Reset()
+		log.SetTimeFormatter(nil).Error().Msg("after")
+		ensureBytes(t, bb.Bytes(), []byte("{\"level\":\"error\",\"message\":\"after\"}\n"))
+	})
+}
+
+func TestWriterDoesNotTerminate(t *testing.T) {
+	defer func(f func(int)) { exitFunc = f }(exitFunc)
+	var exited bool
+	exitFunc = func(int) { exited = true }
+
+	bb := new(bytes.Buffer)
+	log := New(bb).SetError()
+
+	ensureNoPanic(t, "fatal", func() { _, _ = log.NewWriter(Fatal).Write([]byte("cannot continue")) })
+	ensureNoPanic(t, "panic", func() { _, _ = log.NewWriter(Panic).Write([]byte("unexpected state")) })
+
+	if exited {
+		t.Errorf("GOT: %v; WANT: %v", exited, false)
+	}
+	ensureBytes(t, bb.Bytes(), []byte(""+
+		"{\"level\":\"error\",\"message\":\"cannot continue\"}\n"+
+		"{\"level\":\"error\",\"message\":\"unexpected state\"}\n"))
+}
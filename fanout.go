@@ -0,0 +1,197 @@
+package gologs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Destination is an io.Writer to which a FanOut writes events at or above
+// its minimum level.
+type Destination struct {
+	Writer io.Writer
+	Level  Level
+}
+
+// FanOut is a LevelWriter that writes each event to several io.Writers, each
+// with its own minimum level. Each event is written to each io.Writer with a
+// single Write or WriteLevel invocation, and a failure to write to one
+// io.Writer, including a panic, does not prevent writing to the others.
+// Events without a level, which are created by Logger.Log, are written to
+// every io.Writer.
+//
+// Events below the level of their Logger are never created, so the level of
+// a Logger whose io.Writer is a FanOut should be no higher than the lowest
+// level of its destinations.
+//
+// Destinations are written one after another by the goroutine logging the
+// event, so a slow or blocked destination delays the others, along with
+// every goroutine logging to the FanOut. A destination that may be slow, such
+// as a network connection, should be wrapped in an AsyncWriter, which gives
+// it its own queue.
+//
+//	aw := gologs.NewAsyncWriter(sw, 1024, gologs.OverflowDropOldest)
+//	defer aw.Close()
+//	fo := gologs.NewFanOut(
+//	    gologs.Destination{Writer: os.Stderr, Level: gologs.Error},
+//	    gologs.Destination{Writer: lf, Level: gologs.Debug},
+//	    gologs.Destination{Writer: aw, Level: gologs.Info},
+//	)
+//	log := gologs.New(fo).SetDebug()
+type FanOut struct {
+	destinations []*destination
+}
+
+// destination is an io.Writer of a FanOut.
+type destination struct {
+	w     io.Writer
+	lw    LevelWriter // lw is w when w is a LevelWriter, otherwise nil
+	level Level
+	mutex sync.Mutex // mutex ensures only a single Write is invoked at once
+}
+
+// NewFanOut returns a new FanOut that writes to the specified destinations.
+func NewFanOut(destinations ...Destination) *FanOut {
+	fo := &FanOut{destinations: make([]*destination, len(destinations))}
+	for i, d := range destinations {
+		fo.destinations[i] = &destination{w: d.Writer, level: d.Level}
+		fo.destinations[i].lw, _ = d.Writer.(LevelWriter)
+	}
+	return fo
+}
+
+// FanOutError is returned by a FanOut when one or more of its destinations
+// fail.
+type FanOutError struct {
+	// Errors holds the error from each failed destination, each of which
+	// includes the index of its destination.
+	Errors []error
+}
+
+// Error returns the errors from each failed destination.
+func (e *FanOutError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the error from each failed destination.
+func (e *FanOutError) Unwrap() []error { return e.Errors }
+
+// Write writes buf as an event without a level to every destination. See
+// WriteLevel.
+func (fo *FanOut) Write(buf []byte) (int, error) {
+	return fo.WriteLevel(0, buf)
+}
+
+// WriteLevel writes buf, which holds an event at the specified level, to
+// every destination whose level is not above it. It returns the length of buf
+// and nil when every write succeeds, and otherwise a *FanOutError.
+func (fo *FanOut) WriteLevel(level Level, buf []byte) (int, error) {
+	var fe *FanOutError
+	for i, d := range fo.destinations {
+		if level != 0 && level < d.level {
+			continue
+		}
+		if err := d.write(level, buf); err != nil {
+			fe = fe.add(i, "write", err)
+		}
+	}
+	if fe != nil {
+		return len(buf), fe
+	}
+	return len(buf), nil
+}
+
+// Flush flushes every destination that has either a Flush or a Sync method.
+// It returns a *FanOutError when any of them fail.
+func (fo *FanOut) Flush() error {
+	var fe *FanOutError
+	for i, d := range fo.destinations {
+		if err := d.flush(); err != nil {
+			fe = fe.add(i, "flush", err)
+		}
+	}
+	if fe != nil {
+		return fe
+	}
+	return nil
+}
+
+// Reopen reopens every destination that has a Reopen method. It returns a
+// *FanOutError when any of them fail.
+func (fo *FanOut) Reopen() error {
+	var fe *FanOutError
+	for i, d := range fo.destinations {
+		if err := d.reopen(); err != nil {
+			fe = fe.add(i, "reopen", err)
+		}
+	}
+	if fe != nil {
+		return fe
+	}
+	return nil
+}
+
+// add returns fe, which may be nil, with an error for the destination at
+// index i appended to it.
+func (fe *FanOutError) add(i int, action string, err error) *FanOutError {
+	if fe == nil {
+		fe = new(FanOutError)
+	}
+	fe.Errors = append(fe.Errors, fmt.Errorf("cannot %s destination %d: %w", action, i, err))
+	return fe
+}
+
+// write writes buf to the destination, converting a panic into an error.
+func (d *destination) write(level Level, buf []byte) (err error) {
+	d.mutex.Lock()
+
+	// Using defer here to prevent holding lock if underlying io.Writer
+	// panics.
+	defer func() {
+		d.mutex.Unlock()
+		if r := recover(); r != nil {
+			err = fmt.Errorf("io.Writer panicked: %v", r)
+		}
+	}()
+
+	var n int
+	if d.lw != nil {
+		n, err = d.lw.WriteLevel(level, buf)
+	} else {
+		n, err = d.w.Write(buf)
+	}
+	if err == nil && n < len(buf) {
+		err = io.ErrShortWrite
+	}
+	return err
+}
+
+// flush flushes the destination when it has either a Flush or a Sync method.
+func (d *destination) flush() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	switch w := d.w.(type) {
+	case interface{ Flush() error }:
+		return w.Flush()
+	case interface{ Sync() error }:
+		return w.Sync()
+	}
+	return nil
+}
+
+// reopen reopens the destination when it has a Reopen method.
+func (d *destination) reopen() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if w, ok := d.w.(interface{ Reopen() error }); ok {
+		return w.Reopen()
+	}
+	return nil
+}
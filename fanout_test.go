@@ -0,0 +1,101 @@
+package gologs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// shortWriter is a test structure that writes only part of each buffer.
+type shortWriter struct{}
+
+func (shortWriter) Write(buf []byte) (int, error) { return len(buf) / 2, nil }
+
+// flushWriter is a test structure that records how many times it was
+// flushed.
+type flushWriter struct {
+	bytes.Buffer
+	flushes int
+	err     error
+}
+
+func (fw *flushWriter) Flush() error {
+	fw.flushes++
+	return fw.err
+}
+
+func TestFanOut(t *testing.T) {
+	t.Run("levels", func(t *testing.T) {
+		stderr, file := new(bytes.Buffer), new(bytes.Buffer)
+		gw := newGateWriter()
+		close(gw.release)
+
+		fo := NewFanOut(
+			Destination{Writer: stderr, Level: Error},
+			Destination{Writer: file, Level: Debug},
+			Destination{Writer: gw, Level: Info},
+		)
+		log := New(fo).SetDebug()
+
+		log.Debug().Msg("1")
+		log.Info().Msg("2")
+		log.Error().Msg("3")
+		log.Log().Msg("4")
+
+		ensureBytes(t, stderr.Bytes(), []byte("{\"level\":\"error\",\"message\":\"3\"}\n{\"message\":\"4\"}\n"))
+		ensureBytes(t, file.Bytes(), []byte("{\"level\":\"debug\",\"message\":\"1\"}\n{\"level\":\"info\",\"message\":\"2\"}\n{\"level\":\"error\",\"message\":\"3\"}\n{\"message\":\"4\"}\n"))
+		ensureBytes(t, gw.buf.Bytes(), []byte("{\"level\":\"info\",\"message\":\"2\"}\n{\"level\":\"error\",\"message\":\"3\"}\n{\"message\":\"4\"}\n"))
+		if got, want := gw.levels, []Level{Info, Error, 0}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("failure isolation", func(t *testing.T) {
+		before, after := new(bytes.Buffer), new(bytes.Buffer)
+		gw := newGateWriter()
+		close(gw.release)
+		gw.err = errors.New("disk full")
+
+		fo := NewFanOut(
+			Destination{Writer: before},
+			Destination{Writer: &panicyWriter{shouldPanic: true}},
+			Destination{Writer: gw},
+			Destination{Writer: shortWriter{}},
+			Destination{Writer: after},
+		)
+
+		n, err := fo.WriteLevel(Info, []byte("event\n"))
+		if got, want := n, 6; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureError(t, err,
+			"cannot write destination 1: io.Writer panicked: writer-boom!",
+			"cannot write destination 2: disk full",
+			"cannot write destination 3: short write",
+		)
+		var fe *FanOutError
+		if !errors.As(err, &fe) || len(fe.Errors) != 3 {
+			t.Fatalf("GOT: %#v; WANT: 3 errors", err)
+		}
+		if got, want := errors.Unwrap(fe.Errors[1]), gw.err; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureBytes(t, before.Bytes(), []byte("event\n"))
+		ensureBytes(t, after.Bytes(), []byte("event\n"))
+
+		// The panicking destination remains usable.
+		if _, err = fo.WriteLevel(Info, []byte("again\n")); len(err.(*FanOutError).Errors) != 3 {
+			t.Errorf("GOT: %v; WANT: 3 errors", err)
+		}
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		fw := &flushWriter{err: errors.New("disk full")}
+		fo := NewFanOut(Destination{Writer: new(bytes.Buffer)}, Destination{Writer: fw})
+
+		ensureError(t, fo.Flush(), "cannot flush destination 1: disk full")
+		if got, want := fw.flushes, 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+}
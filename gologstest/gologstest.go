@@ -3,7 +3,7 @@
 // It forwards log output to t.Log, so `go test` captures it per-test and
 // prints it only when the test fails (or under -v) — the run-up-to-failure
 // context that io.Discard throws away, while keeping passing tests quiet. It
-// logs at debug verbosity so every level is captured.
+// logs at trace verbosity so every level is captured.
 //
 // Mirrors the well-worn zaptest.NewLogger(t) pattern. It lives in a
 // subpackage on purpose so the core gologs package never imports "testing"
@@ -17,12 +17,12 @@ import (
 	"github.com/karrick/gologs"
 )
 
-// New returns a logger wired to t.Log at debug verbosity. Construct one per
+// New returns a logger wired to t.Log at trace verbosity. Construct one per
 // test: a gologs logger is cheap, and a fresh writer per test keeps tests
 // isolated and parallel-safe (t.Log is per-test and goroutine-safe), unlike
 // sharing one logger and swapping its writer.
 func New(tb testing.TB) *gologs.Logger {
-	return gologs.New(writer{tb}).SetDebug()
+	return gologs.New(writer{tb}).SetTrace()
 }
 
 // writer adapts testing.TB to io.Writer, emitting one t.Log record per log
@@ -0,0 +1,38 @@
+package gologs
+
+// Hook is invoked by Event.Msg for every event of the Logger to which it was
+// added, after the properties of the event have been added, and before the
+// message is added and the event is written. It receives the event, its
+// level, which is zero for events created by Logger.Log, and its message. A
+// Hook may inspect the encoded properties of the event using its Fields
+// method, add more properties to the event using its other methods, and
+// forward a copy of the event elsewhere. It returns false to drop the event,
+// in which case the event is not written, and the remaining hooks are not
+// invoked. A Hook must not invoke the Msg method of the event, and must not
+// log events using the Logger whose event it received.
+//
+//	var errors uint64
+//	log.AddHook(func(e *gologs.Event, level gologs.Level, message string) bool {
+//	    if level >= gologs.Error {
+//	        atomic.AddUint64(&errors, 1)
+//	    }
+//	    return true
+//	})
+type Hook func(event *Event, level Level, message string) bool
+
+// appendHook returns hooks with hook appended to it, without modifying the
+// array of hooks, which may be shared by other Loggers.
+func appendHook(hooks []Hook, hook Hook) []Hook {
+	return append(hooks[:len(hooks):len(hooks)], hook)
+}
+
+// runHooks invokes each of the Event's hooks in order, returning false as
+// soon as one of them drops the event.
+func (event *Event) runHooks(message string) bool {
+	for _, hook := range event.hooks {
+		if !hook(event, event.level, message) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package gologs
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestHook(t *testing.T) {
+	t.Run("observe and mutate", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		counts := make(map[Level]int)
+		var forwarded []string
+
+		log := New(bb).SetInfo().
+			AddHook(func(e *Event, level Level, message string) bool {
+				counts[level]++
+				return true
+			}).
+			AddHook(func(e *Event, level Level, message string) bool {
+				return !strings.HasPrefix(message, "health check")
+			}).
+			AddHook(func(e *Event, level Level, message string) bool {
+				if level >= Error {
+					e.String("alert", "oncall")
+					forwarded = append(forwarded, string(e.Fields())+message)
+				}
+				return true
+			})
+		branch := log.With().String("module", "server").Logger()
+
+		log.Info().Msg("started")
+		log.Info().Msg("health check passed")
+		branch.Error().Int("status", 500).Msg("cannot respond")
+		branch.Log().Msg("")
+		log.Debug().Msg("below level")
+
+		ensureBytes(t, bb.Bytes(), []byte(""+
+			"{\"level\":\"info\",\"message\":\"started\"}\n"+
+			"{\"level\":\"error\",\"module\":\"server\",\"status\":500,\"alert\":\"oncall\",\"message\":\"cannot respond\"}\n"+
+			"{\"module\":\"server\"}\n"))
+		if got, want := counts, map[Level]int{Info: 2, Error: 1, 0: 1}; len(got) != len(want) || got[Info] != want[Info] || got[Error] != want[Error] || got[0] != want[0] {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := strings.Join(forwarded, "|"), `"module":"server","status":500,"alert":"oncall",cannot respond`; got != want {
+			t.Errorf("\nGOT:  %q\nWANT: %q\n", got, want)
+		}
+	})
+
+	t.Run("inheritance", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		tag := func(name string) Hook {
+			return func(e *Event, level Level, message string) bool {
+				e.Bool(name, true)
+				return true
+			}
+		}
+
+		parent := New(bb).SetInfo().AddHook(tag("parent"))
+		child := parent.With().Hook(tag("child")).Logger()
+		sibling := parent.With().Logger()
+		parent.AddHook(tag("late")) // not inherited by existing branches
+		w := child.NewWriter(Info)
+
+		parent.Info().Msg("1")
+		child.Info().Msg("2")
+		sibling.Info().Msg("3")
+		_, _ = w.Write([]byte("4"))
+
+		ensureBytes(t, bb.Bytes(), []byte(""+
+			"{\"level\":\"info\",\"parent\":true,\"late\":true,\"message\":\"1\"}\n"+
+			"{\"level\":\"info\",\"parent\":true,\"child\":true,\"message\":\"2\"}\n"+
+			"{\"level\":\"info\",\"parent\":true,\"message\":\"3\"}\n"+
+			"{\"level\":\"info\",\"parent\":true,\"child\":true,\"message\":\"4\"}\n"))
+	})
+
+	t.Run("encoder", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		var fields string
+		log := NewWithEncoder(bb, LogfmtEncoder{}).SetInfo().
+			With().String("module", "server").Logger().
+			AddHook(func(e *Event, level Level, message string) bool {
+				fields = string(e.Fields())
+				return true
+			})
+
+		log.Info().Int("status", 200).Msg("done")
+		ensureBytes(t, bb.Bytes(), []byte("level=info module=server status=200 message=done\n"))
+		if got, want := fields, "module=server status=200 "; got != want {
+			t.Errorf("\nGOT:  %q\nWANT: %q\n", got, want)
+		}
+	})
+
+	t.Run("allocations", func(t *testing.T) {
+		log := New(io.Discard).SetInfo()
+		emit := func() { log.Info().String("module", "server").Msg("started") }
+
+		if got := testing.AllocsPerRun(100, emit); got != 0 {
+			t.Errorf("GOT: %v; WANT: %v", got, 0)
+		}
+		log.AddHook(func(e *Event, level Level, message string) bool { return true })
+		if got := testing.AllocsPerRun(100, emit); got != 0 {
+			t.Errorf("GOT: %v; WANT: %v", got, 0)
+		}
+	})
+}
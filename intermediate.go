@@ -1,29 +1,100 @@
 package gologs
 
+import "time"
+
 // Intermediate is an intermediate Logger that is not capable of logging
 // events, but used while creating a new Logger that always includes one or
 // more properties in each logged event.
 //
 // Logger.With() -> *Intermediate -> Bool() -> *Intermediate -> ... -> Logger() -> *Logger
 type Intermediate struct {
-	branch        []byte // branch holds potentially empty prefix of each log event
-	timeFormatter TimeFormatter
-	output        *output
-	level         uint32
-	tracing       bool
+	branch            []byte // branch holds potentially empty prefix of each log event
+	timeFormatter     TimeFormatter
+	durationFormatter DurationFormatter
+	output            *output
+	encoder           Encoder
+	nested            Object // nested is reused for every nested object and array
+	hooks             []Hook
+	caller            caller
+	keys              keys
+	sampler           Sampler
+	counting          bool
+	level             uint32
+	tracing           bool
+}
+
+// Array returns a new Intermediate Logger that has the name property set to a
+// JSON array, whose elements are added by callback.
+func (il *Intermediate) Array(name string, callback func(*Array)) *Intermediate {
+	il.nested.durationFormatter = il.durationFormatter
+	start := len(il.branch)
+	il.branch = appendArray(il.branch, name, &il.nested, callback)
+	il.reencode(start)
+	return il
 }
 
 // Bool returns a new Intermediate Logger that has the name property set to
 // the JSON encoded bool value.
 func (il *Intermediate) Bool(name string, value bool) *Intermediate {
+	start := len(il.branch)
 	il.branch = appendBool(il.branch, name, value)
+	il.reencode(start)
+	return il
+}
+
+// Caller returns a new Intermediate Logger that adds the caller property to
+// each event when enabled is true, set to the location of the source code that
+// invoked the Msg method of the event, for instance "server/handler.go:42".
+// Because it must walk the stack, annotating events with their caller has a
+// cost, which is only paid by events that are logged. See CallerSkip and
+// CallerTrimmer.
+//
+//	log = log.With().Caller(true).Logger()
+func (il *Intermediate) Caller(enabled bool) *Intermediate {
+	il.caller.enabled = enabled
+	return il
+}
+
+// CallerSkip returns a new Intermediate Logger that skips the specified
+// number of additional stack frames when it determines the caller and the
+// stack trace of an event. This allows a function that wraps a Logger to
+// report the location of its own callers.
+func (il *Intermediate) CallerSkip(skip int) *Intermediate {
+	il.caller.skip = skip
+	return il
+}
+
+// CallerTrimmer returns a new Intermediate Logger that uses trimmer to format
+// the pathname in the caller property of its events. When no CallerTrimmer is
+// provided, CallerShort is used.
+func (il *Intermediate) CallerTrimmer(trimmer CallerTrimmer) *Intermediate {
+	il.caller.trimmer = trimmer
+	return il
+}
+
+// Counting returns a new Intermediate Logger that counts its events by level
+// when enabled is true. See Logger.SetCounting.
+func (il *Intermediate) Counting(enabled bool) *Intermediate {
+	il.counting = enabled
+	return il
+}
+
+// Duration returns a new Intermediate Logger that has the name property set
+// to the JSON encoded time.Duration value, formatted by the Logger's
+// DurationFormatter.
+func (il *Intermediate) Duration(name string, value time.Duration) *Intermediate {
+	start := len(il.branch)
+	il.branch = appendDuration(il.branch, name, value, il.durationFormatter)
+	il.reencode(start)
 	return il
 }
 
 // Float returns a new Intermediate Logger that has the name property set to
 // the JSON encoded float64 value.
 func (il *Intermediate) Float(name string, value float64) *Intermediate {
+	start := len(il.branch)
 	il.branch = appendFloat(il.branch, name, value)
+	il.reencode(start)
 	return il
 }
 
@@ -34,32 +105,58 @@ func (il *Intermediate) Float(name string, value float64) *Intermediate {
 // so. If no formatting is required, invoking Intermediate.String(string,
 // string) will be faster.
 func (il *Intermediate) Format(name, f string, args ...interface{}) *Intermediate {
+	start := len(il.branch)
 	il.branch = appendFormat(il.branch, name, f, args...)
+	il.reencode(start)
+	return il
+}
+
+// Hook returns a new Intermediate Logger that invokes hook for every event,
+// after the hooks it inherited from its parent.
+func (il *Intermediate) Hook(hook Hook) *Intermediate {
+	il.hooks = appendHook(il.hooks, hook)
 	return il
 }
 
 // Int returns a new Intermediate Logger that has the name property set to the
 // JSON encoded int value.
 func (il *Intermediate) Int(name string, value int) *Intermediate {
+	start := len(il.branch)
 	il.branch = appendInt(il.branch, name, int64(value))
+	il.reencode(start)
 	return il
 }
 
 // Int64 returns a new Intermediate Logger that has the name property set to
 // the JSON encoded int64 value.
 func (il *Intermediate) Int64(name string, value int64) *Intermediate {
+	start := len(il.branch)
 	il.branch = appendInt(il.branch, name, value)
+	il.reencode(start)
 	return il
 }
 
+// reencode gives the Encoder, if there is one, the chance to re-encode the
+// property that was appended to the branch after start.
+func (il *Intermediate) reencode(start int) {
+	if il.encoder != nil {
+		il.branch = il.encoder.Property(il.branch, start)
+	}
+}
+
 // Logger converts the Intermediate Logger into a new Logger instance that
 // includes the fields it was configured to contain.
 func (il *Intermediate) Logger() *Logger {
 	log := &Logger{
 		event: Event{
-			scratch:       make([]byte, 1, 2048),
-			timeFormatter: il.timeFormatter,
-			output:        il.output,
+			scratch:           newScratch(il.encoder),
+			timeFormatter:     il.timeFormatter,
+			durationFormatter: il.durationFormatter,
+			output:            il.output,
+			encoder:           il.encoder,
+			hooks:             il.hooks,
+			caller:            il.caller,
+			keys:              il.keys,
 		},
 		level:   il.level,
 		tracing: il.tracing,
@@ -68,15 +165,57 @@ func (il *Intermediate) Logger() *Logger {
 		log.branch = make([]byte, len(il.branch), cap(il.branch))
 		copy(log.branch, il.branch)
 	}
-	log.event.scratch[0] = '{'
+	log.event.prefix = len(log.event.scratch)
+	log.event.counters = &log.counters
+	log.counters.setEnabled(il.counting)
+	if il.sampler != nil {
+		log.sampler.Store(sampler{il.sampler})
+	}
 
 	return log
 }
 
+// Object returns a new Intermediate Logger that has the name property set to
+// a JSON object, whose properties are added by callback.
+func (il *Intermediate) Object(name string, callback func(*Object)) *Intermediate {
+	il.nested.durationFormatter = il.durationFormatter
+	start := len(il.branch)
+	il.branch = appendObject(il.branch, name, &il.nested, callback)
+	il.reencode(start)
+	return il
+}
+
+// Sampler returns a new Intermediate Logger that uses the specified Sampler
+// to decide which events it logs, rather than the Sampler of its parent. A nil
+// Sampler causes the new Logger to log every event its level allows.
+func (il *Intermediate) Sampler(s Sampler) *Intermediate {
+	il.sampler = s
+	return il
+}
+
+// StackOnError returns a new Intermediate Logger that adds the stack property
+// to each event at the Error level or above when enabled is true, unless the
+// stack property was already added using Event.Stack. See Event.Stack.
+func (il *Intermediate) StackOnError(enabled bool) *Intermediate {
+	il.caller.stackOnError = enabled
+	return il
+}
+
 // String returns a new Intermediate Logger that has the name property set to
 // the JSON encoded string value.
 func (il *Intermediate) String(name, value string) *Intermediate {
+	start := len(il.branch)
 	il.branch = appendString(il.branch, name, value)
+	il.reencode(start)
+	return il
+}
+
+// Time returns a new Intermediate Logger that has the name property set to
+// the JSON encoded time.Time value, formatted using the specified layout.
+func (il *Intermediate) Time(name string, value time.Time, layout string) *Intermediate {
+	start := len(il.branch)
+	il.branch = appendTime(il.branch, name, value, layout)
+	il.reencode(start)
 	return il
 }
 
@@ -90,13 +229,17 @@ func (il *Intermediate) Tracing(value bool) *Intermediate {
 // Uint returns a new Intermediate Logger that has the name property set to
 // the JSON encoded uint value.
 func (il *Intermediate) Uint(name string, value uint) *Intermediate {
+	start := len(il.branch)
 	il.branch = appendUint(il.branch, name, uint64(value))
+	il.reencode(start)
 	return il
 }
 
 // Uint64 returns a new Intermediate Logger that has the name property set to
 // the JSON encoded uint64 value.
 func (il *Intermediate) Uint64(name string, value uint64) *Intermediate {
+	start := len(il.branch)
 	il.branch = appendUint(il.branch, name, value)
+	il.reencode(start)
 	return il
 }
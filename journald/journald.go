@@ -0,0 +1,519 @@
+// Package journald provides an io.Writer that sends log events to the systemd
+// journal using its native protocol, preserving the properties of each event
+// as journal fields.
+//
+//	jw, err := journald.Dial("")
+//	if err != nil {
+//	    panic(err)
+//	}
+//	defer jw.Close()
+//	log := gologs.New(jw)
+//
+// The message of a JSON event becomes the MESSAGE field, and its level
+// becomes the PRIORITY field rather than a field of its own. Each other
+// property of the event, including the properties of its branch, becomes a
+// journal field whose name is the property name converted to upper case,
+// with each character other than a letter, digit, or underscore replaced by
+// an underscore. A property whose field name would be MESSAGE or PRIORITY is
+// sent with the USER_ prefix instead, so neither field can be overridden.
+// String values are sent without their quotes, and other values are sent as
+// JSON. When the Logger uses other names for its level and message
+// properties, the Writer must be told using SetLevelKey and SetMessageKey.
+// An event without a property named by the message key, such as an event
+// with an empty message, or an event from a Logger whose message key the
+// Writer was not told, is sent in its entirety as the MESSAGE field, so
+// nothing is lost. The PRIORITY field is derived from the level of the
+// event like the syslog severity:
+//
+//	Trace, Debug, Verbose: 7 (debug)
+//	Info:                  6 (informational)
+//	Warning:               4 (warning)
+//	Error:                 3 (err)
+//	Fatal:                 2 (crit)
+//	Panic:                 1 (alert)
+//	no level (Logger.Log): 5 (notice)
+//
+// An event that is not a JSON object, for instance because the Logger uses
+// another Encoder, is sent as the MESSAGE field. Events are sent as single
+// datagrams, so events larger than the maximum datagram size of the journal
+// socket cannot be sent.
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/karrick/gologs"
+)
+
+// DefaultSocket is the pathname of the socket where the systemd journal
+// receives events using its native protocol.
+const DefaultSocket = "/run/systemd/journal/socket"
+
+// maxFieldName is the length limit of journal field names.
+const maxFieldName = 64
+
+// reservedPrefix is prepended to the field names of properties that would
+// otherwise be sent as the MESSAGE or PRIORITY field.
+const reservedPrefix = "USER_"
+
+// Writer is a gologs.LevelWriter that sends each event to the systemd
+// journal. It is safe for concurrent use.
+type Writer struct {
+	pathname   string
+	levelKey   string // levelKey is the name of the level property of events
+	messageKey string // messageKey is the name of the message property of events
+	conn       net.Conn
+	buf        []byte     // buf holds the datagram of the event being sent
+	name       []byte     // name holds the unescaped name of a property
+	properties []property // properties holds the properties of the event being sent
+	mutex      sync.Mutex
+}
+
+// Dial returns a new Writer that sends events to the journal socket at
+// pathname, or at DefaultSocket when pathname is empty.
+func Dial(pathname string) (*Writer, error) {
+	if pathname == "" {
+		pathname = DefaultSocket
+	}
+	w := &Writer{pathname: pathname, levelKey: "level", messageKey: "message"}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// SetLevelKey changes the name of the level property of events, which must
+// match the level key of the Logger writing to the Writer.
+//
+//	log := gologs.New(jw.SetLevelKey("severity")).SetLevelKey("severity")
+func (w *Writer) SetLevelKey(key string) *Writer {
+	w.mutex.Lock()
+	w.levelKey = key
+	w.mutex.Unlock()
+	return w
+}
+
+// SetMessageKey changes the name of the message property of events, which
+// must match the message key of the Logger writing to the Writer.
+//
+//	log := gologs.New(jw.SetMessageKey("msg")).SetMessageKey("msg")
+func (w *Writer) SetMessageKey(key string) *Writer {
+	w.mutex.Lock()
+	w.messageKey = key
+	w.mutex.Unlock()
+	return w
+}
+
+// Write sends buf as an event without a level, which has the notice
+// priority.
+func (w *Writer) Write(buf []byte) (int, error) {
+	return w.WriteLevel(0, buf)
+}
+
+// WriteLevel sends buf, which holds an event at the specified level, to the
+// journal. When sending fails, the Writer reconnects to the journal socket and
+// sends the event once more.
+func (w *Writer) WriteLevel(level gologs.Level, buf []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.buf = w.appendEvent(w.buf[:0], level, buf)
+
+	if w.conn != nil {
+		if _, err := w.conn.Write(w.buf); err == nil {
+			return len(buf), nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	if err := w.connect(); err != nil {
+		return 0, err
+	}
+	if _, err := w.conn.Write(w.buf); err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		return 0, fmt.Errorf("cannot write to journal: %w", err)
+	}
+	return len(buf), nil
+}
+
+// Close closes the connection to the journal socket.
+func (w *Writer) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// connect connects to the journal socket. It must be invoked while the
+// Writer is locked or before the Writer is shared.
+func (w *Writer) connect() error {
+	conn, err := net.Dial("unixgram", w.pathname)
+	if err != nil {
+		return fmt.Errorf("cannot connect to journal: %w", err)
+	}
+	w.conn = conn
+	return nil
+}
+
+// appendEvent appends the journal fields of the event in buf, which has the
+// specified level, to dst. It must be invoked while the Writer is locked.
+func (w *Writer) appendEvent(dst []byte, level gologs.Level, buf []byte) []byte {
+	dst = append(dst, "PRIORITY="...)
+	dst = strconv.AppendInt(dst, int64(priority(level)), 10)
+	dst = append(dst, '\n')
+
+	buf = bytes.TrimRight(buf, "\n")
+	var ok bool
+	w.properties, ok = appendProperties(w.properties[:0], buf)
+	if !ok {
+		return appendField(dst, "MESSAGE", buf)
+	}
+
+	// The message is the final property with the message key, because the
+	// Logger appends it after every other property. The level is the first
+	// property with the level key, because the Logger prepends it to every
+	// other property except the time.
+	message, levelIndex := -1, -1
+	for i, p := range w.properties {
+		name := w.unescapeName(p.name)
+		if string(name) == w.messageKey {
+			message = i
+		}
+		if level != 0 && levelIndex < 0 && string(name) == w.levelKey {
+			levelIndex = i
+		}
+	}
+
+	for i, p := range w.properties {
+		if i == levelIndex || i == message {
+			continue // sent as PRIORITY, or as MESSAGE below
+		}
+		start := len(dst)
+		dst = appendFieldName(dst, w.unescapeName(p.name))
+		switch string(dst[start:]) {
+		case "MESSAGE":
+			dst = append(dst[:start], reservedPrefix+"MESSAGE"...)
+		case "PRIORITY":
+			dst = append(dst[:start], reservedPrefix+"PRIORITY"...)
+		}
+		dst = appendValue(append(dst, '='), p.value)
+	}
+
+	if message < 0 {
+		return appendField(dst, "MESSAGE", buf)
+	}
+	return appendValue(append(dst, "MESSAGE="...), w.properties[message].value)
+}
+
+// unescapeName returns the name of a property without its escape sequences.
+// The returned byte slice is only valid until the next invocation. It must be
+// invoked while the Writer is locked.
+func (w *Writer) unescapeName(name []byte) []byte {
+	if bytes.IndexByte(name, '\\') == -1 {
+		return name
+	}
+	w.name = appendUnescaped(w.name[:0], name)
+	return w.name
+}
+
+// property is a property of a JSON event. Both the name, without its
+// enclosing double quotes, and the value are slices of the event, and retain
+// their escape sequences.
+type property struct {
+	name  []byte
+	value []byte
+}
+
+// appendProperties appends the properties of the JSON object in buf, in
+// order, to properties, and returns false when buf is not a JSON object.
+func appendProperties(properties []property, buf []byte) ([]property, bool) {
+	i := skipSpace(buf, 0)
+	if i == len(buf) || buf[i] != '{' {
+		return properties, false
+	}
+	i = skipSpace(buf, i+1)
+	if i < len(buf) && buf[i] == '}' {
+		return properties, skipSpace(buf, i+1) == len(buf)
+	}
+
+	for i < len(buf) && buf[i] == '"' {
+		end := skipString(buf, i)
+		if end == -1 {
+			return properties, false
+		}
+		name := buf[i+1 : end-1]
+
+		i = skipSpace(buf, end)
+		if i == len(buf) || buf[i] != ':' {
+			return properties, false
+		}
+		i = skipSpace(buf, i+1)
+		if end = skipValue(buf, i); end == -1 {
+			return properties, false
+		}
+		properties = append(properties, property{name: name, value: buf[i:end]})
+
+		i = skipSpace(buf, end)
+		if i == len(buf) {
+			return properties, false
+		}
+		switch buf[i] {
+		case '}':
+			return properties, skipSpace(buf, i+1) == len(buf)
+		case ',':
+			i = skipSpace(buf, i+1)
+		default:
+			return properties, false
+		}
+	}
+	return properties, false
+}
+
+// skipSpace returns the offset of the first byte of buf at or after i that is
+// not JSON whitespace.
+func skipSpace(buf []byte, i int) int {
+	for i < len(buf) && (buf[i] == ' ' || buf[i] == '\t' || buf[i] == '\n' || buf[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+// skipString returns the offset of the byte following the JSON string that
+// begins at offset i of buf, or -1 when the string is not terminated.
+func skipString(buf []byte, i int) int {
+	// Skip past the opening quote, then find the closing quote, which is the
+	// first double quote not escaped by a backslash.
+	for i++; i < len(buf); i++ {
+		switch buf[i] {
+		case '\\':
+			i++ // skip escaped character
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// skipValue returns the offset of the byte following the JSON value that
+// begins at offset i of buf, or -1 when there is no such value.
+func skipValue(buf []byte, i int) int {
+	if i == len(buf) {
+		return -1
+	}
+	switch buf[i] {
+	case '"':
+		return skipString(buf, i)
+	case '{', '[':
+		var depth int
+		for i < len(buf) {
+			switch buf[i] {
+			case '"':
+				if i = skipString(buf, i); i == -1 {
+					return -1
+				}
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				if depth--; depth == 0 {
+					return i + 1
+				}
+			}
+			i++
+		}
+		return -1
+	}
+
+	// Numbers, and the true, false, and null literals.
+	start := i
+	for i < len(buf) && buf[i] != ',' && buf[i] != '}' && skipSpace(buf, i) == i {
+		i++
+	}
+	if i == start {
+		return -1
+	}
+	return i
+}
+
+// appendUnescaped appends s, the contents of a JSON string without its
+// enclosing double quotes, to dst after replacing its escape sequences with
+// the characters they represent.
+func appendUnescaped(dst, s []byte) []byte {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			dst = append(dst, s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'b':
+			dst = append(dst, '\b')
+		case 'f':
+			dst = append(dst, '\f')
+		case 'n':
+			dst = append(dst, '\n')
+		case 'r':
+			dst = append(dst, '\r')
+		case 't':
+			dst = append(dst, '\t')
+		case 'u':
+			r, n := decodeEscapedRune(s[i+1:])
+			i += n
+			var encoded [utf8.UTFMax]byte
+			dst = append(dst, encoded[:utf8.EncodeRune(encoded[:], r)]...)
+		default:
+			dst = append(dst, s[i]) // double quote, backslash, or slash
+		}
+	}
+	return dst
+}
+
+// decodeEscapedRune returns the rune encoded by the hexadecimal digits that
+// follow a \u escape sequence in s, along with the number of bytes of s it
+// used. A surrogate pair encoded as two consecutive \u escape sequences
+// results in a single rune. Invalid sequences result in utf8.RuneError.
+func decodeEscapedRune(s []byte) (rune, int) {
+	r, ok := parseHex4(s)
+	if !ok {
+		return utf8.RuneError, 0
+	}
+	if utf16.IsSurrogate(r) && len(s) >= 10 && s[4] == '\\' && s[5] == 'u' {
+		if r2, ok := parseHex4(s[6:]); ok {
+			if pair := utf16.DecodeRune(r, r2); pair != utf8.RuneError {
+				return pair, 10
+			}
+		}
+	}
+	if utf16.IsSurrogate(r) {
+		return utf8.RuneError, 4
+	}
+	return r, 4
+}
+
+// parseHex4 returns the value of the four hexadecimal digits that begin s.
+func parseHex4(s []byte) (rune, bool) {
+	if len(s) < 4 {
+		return 0, false
+	}
+	var r rune
+	for _, c := range s[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c -= 'a' - 10
+		case c >= 'A' && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}
+
+// appendField appends a journal field with the specified name and value to
+// dst.
+func appendField(dst []byte, name string, value []byte) []byte {
+	dst = append(dst, name...)
+	dst = append(dst, '=')
+	start := len(dst)
+	return endField(append(dst, value...), start)
+}
+
+// appendValue appends the value of a journal field, which follows its name
+// and equal sign already appended to dst. String values are appended without
+// their enclosing double quotes and escape sequences, and other values are
+// appended as JSON.
+func appendValue(dst, value []byte) []byte {
+	start := len(dst)
+	if len(value) > 1 && value[0] == '"' {
+		dst = appendUnescaped(dst, value[1:len(value)-1])
+	} else {
+		dst = append(dst, value...)
+	}
+	return endField(dst, start)
+}
+
+// endField terminates the journal field whose value begins at offset start of
+// dst, immediately after an equal sign. Values that contain a newline are
+// prefixed by their length rather than terminated by a newline, so the equal
+// sign is replaced by a newline followed by the length.
+func endField(dst []byte, start int) []byte {
+	if bytes.IndexByte(dst[start:], '\n') == -1 {
+		return append(dst, '\n')
+	}
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(len(dst)-start))
+	end := len(dst)
+	dst = append(dst, length[:]...)
+	copy(dst[start+len(length):], dst[start:end])
+	copy(dst[start:], length[:])
+	dst[start-1] = '\n'
+	return append(dst, '\n')
+}
+
+// appendFieldName appends the journal field name for a property name to dst.
+// Journal field names may only contain upper case letters, digits, and
+// underscores, and may not begin with either a digit or an underscore, which
+// is reserved for fields added by the journal itself.
+func appendFieldName(dst, name []byte) []byte {
+	start := len(dst)
+	for _, c := range name {
+		if len(dst)-start == maxFieldName {
+			break
+		}
+		switch {
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c >= 'a' && c <= 'z':
+			c -= 'a' - 'A'
+		case len(dst) == start:
+			continue // trim leading underscores
+		default:
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+	if len(dst) == start || (dst[start] >= '0' && dst[start] <= '9') {
+		dst = append(dst, 0)
+		copy(dst[start+1:], dst[start:])
+		dst[start] = 'X'
+		if len(dst)-start > maxFieldName {
+			dst = dst[:start+maxFieldName]
+		}
+	}
+	return dst
+}
+
+// priority returns the journal priority, which is a syslog severity, for
+// level.
+func priority(level gologs.Level) int {
+	switch {
+	case level == 0:
+		return 5 // notice
+	case level < gologs.Info:
+		return 7 // debug
+	case level < gologs.Warning:
+		return 6 // informational
+	case level < gologs.Error:
+		return 4 // warning
+	case level < gologs.Fatal:
+		return 3 // err
+	case level < gologs.Panic:
+		return 2 // crit
+	}
+	return 1 // alert
+}
@@ -0,0 +1,185 @@
+package journald
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/karrick/gologs"
+)
+
+// listenTest returns a unixgram socket standing in for the journal socket,
+// and a Writer connected to it.
+func listenTest(tb testing.TB) (net.PacketConn, *Writer) {
+	tb.Helper()
+	if runtime.GOOS == "windows" {
+		tb.Skip("unixgram sockets are not supported")
+	}
+
+	// Socket pathnames are limited in length, so avoid a deeply nested
+	// temporary directory.
+	dir, err := os.MkdirTemp("", "journald")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { _ = os.RemoveAll(dir) })
+	pathname := filepath.Join(dir, "socket")
+
+	pc, err := net.ListenPacket("unixgram", pathname)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { _ = pc.Close() })
+
+	w, err := Dial(pathname)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { _ = w.Close() })
+	return pc, w
+}
+
+// readPacket returns the next datagram received by pc.
+func readPacket(tb testing.TB, pc net.PacketConn) string {
+	tb.Helper()
+	if err := pc.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		tb.Fatal(err)
+	}
+	buf := make([]byte, 4096)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return string(buf[:n])
+}
+
+func ensureString(tb testing.TB, got, want string) {
+	tb.Helper()
+	if got != want {
+		tb.Errorf("\nGOT:  %q\nWANT: %q\n", got, want)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	pc, w := listenTest(t)
+	log := gologs.New(w).SetInfo()
+
+	t.Run("fields", func(t *testing.T) {
+		log.With().String("module", "server").Logger().
+			Warning().
+			Int("status-code", 503).
+			Bool("retry", true).
+			Object("request", func(o *gologs.Object) { o.String("method", "GET") }).
+			Msg("slow request")
+		ensureString(t, readPacket(t, pc), "PRIORITY=4\n"+
+			"MODULE=server\n"+
+			"STATUS_CODE=503\n"+
+			"RETRY=true\n"+
+			"REQUEST={\"method\":\"GET\"}\n"+
+			"MESSAGE=slow request\n")
+	})
+
+	t.Run("reserved names", func(t *testing.T) {
+		log.Warning().
+			String("priority", "high").
+			String("message", "from user").
+			String("level", "from user").
+			Msg("real message")
+		ensureString(t, readPacket(t, pc), "PRIORITY=4\n"+
+			"USER_PRIORITY=high\n"+
+			"USER_MESSAGE=from user\n"+
+			"LEVEL=from user\n"+
+			"MESSAGE=real message\n")
+	})
+
+	t.Run("multiple lines", func(t *testing.T) {
+		log.Log().Msg("one\ntwo")
+		ensureString(t, readPacket(t, pc), "PRIORITY=5\n"+
+			"MESSAGE\n\x07\x00\x00\x00\x00\x00\x00\x00one\ntwo\n")
+	})
+
+	t.Run("escaped", func(t *testing.T) {
+		log.Info().String("path\tname", "C:\\tmp \"caf\u00e9\" \U0001F600").Msg("done")
+		ensureString(t, readPacket(t, pc), "PRIORITY=6\n"+
+			"PATH_NAME=C:\\tmp \"caf\u00e9\" \U0001F600\n"+
+			"MESSAGE=done\n")
+	})
+
+	t.Run("foreign json", func(t *testing.T) {
+		if _, err := w.Write([]byte(" { \"a\" : [1, {\"b\": \"]\"}] , \"msg\\u00e9\":\"\\ud83d\\ude00\\n\", \"message\" : null } \n")); err != nil {
+			t.Fatal(err)
+		}
+		ensureString(t, readPacket(t, pc), "PRIORITY=5\n"+
+			"A=[1, {\"b\": \"]\"}]\n"+
+			"MSG__\n\x05\x00\x00\x00\x00\x00\x00\x00\U0001F600\n\n"+
+			"MESSAGE=null\n")
+	})
+
+	t.Run("not json", func(t *testing.T) {
+		if _, err := w.WriteLevel(gologs.Error, []byte("level=error message=\"cannot open\"\n")); err != nil {
+			t.Fatal(err)
+		}
+		ensureString(t, readPacket(t, pc), "PRIORITY=3\n"+
+			"MESSAGE=level=error message=\"cannot open\"\n")
+	})
+}
+
+func TestWriterKeys(t *testing.T) {
+	pc, w := listenTest(t)
+	log := gologs.New(w.SetLevelKey("severity").SetMessageKey("msg")).
+		SetLevelKey("severity").
+		SetMessageKey("msg")
+
+	log.Error().String("message", "from user").Msg("real message")
+	ensureString(t, readPacket(t, pc), "PRIORITY=3\n"+
+		"USER_MESSAGE=from user\n"+
+		"MESSAGE=real message\n")
+
+	log.Log().String("severity", "kept").Msg("")
+	ensureString(t, readPacket(t, pc), "PRIORITY=5\n"+
+		"SEVERITY=kept\n"+
+		"MESSAGE={\"severity\":\"kept\"}\n")
+
+	t.Run("mismatch", func(t *testing.T) {
+		gologs.New(w).Warning().Msg("real message")
+		ensureString(t, readPacket(t, pc), "PRIORITY=4\n"+
+			"LEVEL=warning\n"+
+			"USER_MESSAGE=real message\n"+
+			"MESSAGE={\"level\":\"warning\",\"message\":\"real message\"}\n")
+	})
+}
+
+func TestWriterAllocations(t *testing.T) {
+	_, w := listenTest(t)
+	event := []byte("{\"level\":\"warning\",\"module\":\"server\",\"status\":503,\"path\":\"/tmp/\\\"foo\\\"\",\"message\":\"slow\\nrequest\"}\n")
+	w.buf = w.appendEvent(w.buf[:0], gologs.Warning, event)
+
+	emit := func() { w.buf = w.appendEvent(w.buf[:0], gologs.Warning, event) }
+	if got := testing.AllocsPerRun(100, emit); got != 0 {
+		t.Errorf("GOT: %v; WANT: %v", got, 0)
+	}
+}
+
+func TestFieldName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"message", "MESSAGE"},
+		{"requestID", "REQUESTID"},
+		{"http.status-code", "HTTP_STATUS_CODE"},
+		{"_private", "PRIVATE"},
+		{"2xx", "X2XX"},
+		{"", "X"},
+		{"a0123456789012345678901234567890123456789012345678901234567890123456789", "A012345678901234567890123456789012345678901234567890123456789012"},
+	}
+
+	for _, single := range tests {
+		t.Run(single.name, func(t *testing.T) {
+			ensureString(t, string(appendFieldName(nil, []byte(single.name))), single.want)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package gologs
+
+// keys holds the names of the properties every event may have. The time,
+// level, and message names are JSON encoded and followed by a colon, so they
+// are encoded once when they are changed rather than for every event. The
+// level and message names are also kept as provided, for Encoders.
+type keys struct {
+	time        string // time is the encoded name that replaces `"time":`
+	level       string // level is the encoded name that replaces `"level":`
+	message     string // message is the encoded name that replaces `"message":`
+	levelName   string // levelName is the name given to Encoder.Level
+	messageName string // messageName is the name given to Encoder.End
+	error       string // error is the name of the property written by Event.Err
+}
+
+const (
+	defaultTimeKey    = `"time":`
+	defaultLevelKey   = `"level":`
+	defaultMessageKey = `"message":`
+)
+
+// defaultKeys are the property names used unless a Logger is configured
+// otherwise.
+var defaultKeys = keys{
+	time:        defaultTimeKey,
+	level:       defaultLevelKey,
+	message:     defaultMessageKey,
+	levelName:   "level",
+	messageName: "message",
+	error:       "error",
+}
+
+// encodeKey returns name JSON encoded and followed by a colon.
+func encodeKey(name string) string {
+	return string(append(appendEncodedJSONFromString(nil, name), ':'))
+}
+
+// renameProperty replaces the encoded property name from with to, when the
+// property that begins at start in buf has that name, and returns the
+// modified byte slice.
+func renameProperty(buf []byte, start int, from, to string) []byte {
+	end := start + len(from)
+	if from == to || len(buf) < end || string(buf[start:end]) != from {
+		return buf
+	}
+	value := len(buf) - end // length of the value following the name
+	if grow := len(to) - len(from); grow > 0 {
+		buf = append(buf, to[:grow]...) // any bytes, overwritten below
+	}
+	copy(buf[start+len(to):], buf[end:end+value])
+	copy(buf[start:], to)
+	return buf[:start+len(to)+value]
+}
+
+// setKeys updates the property names of the Event, potentially blocking until
+// any in progress log event has been written.
+func (event *Event) setKeys(update func(*keys)) {
+	event.mutex.Lock()
+	update(&event.keys)
+	event.mutex.Unlock()
+}
@@ -0,0 +1,125 @@
+package gologs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestKeys(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		New(bb).SetTimeFormatter(counterTime()).Warning().Err(io.EOF).Msg("done")
+		ensureBytes(t, bb.Bytes(), []byte("{\"time\":1,\"level\":\"warning\",\"error\":\"EOF\",\"message\":\"done\"}\n"))
+	})
+
+	t.Run("branches and writers", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		log := New(bb).SetInfo().SetTimeFormatter(counterTime()).
+			SetTimeKey("@timestamp").
+			SetLevelKey("severity").
+			SetMessageKey("msg").
+			SetErrorKey("err")
+		branch := log.With().String("module", "server").Logger()
+
+		log.Info().Msg("started")
+		branch.Error().Err(io.EOF).Msg("cannot read")
+		branch.WithLevel(testNotice).Msg("")
+		_, _ = branch.NewWriter(Warning).Write([]byte("line"))
+		log.Log().Msg("no level")
+
+		ensureBytes(t, bb.Bytes(), []byte(""+
+			"{\"@timestamp\":1,\"severity\":\"info\",\"msg\":\"started\"}\n"+
+			"{\"@timestamp\":2,\"severity\":\"error\",\"module\":\"server\",\"err\":\"EOF\",\"msg\":\"cannot read\"}\n"+
+			"{\"@timestamp\":3,\"severity\":\"notice\",\"module\":\"server\"}\n"+
+			"{\"@timestamp\":4,\"severity\":\"warning\",\"module\":\"server\",\"msg\":\"line\"}\n"+
+			"{\"@timestamp\":5,\"msg\":\"no level\"}\n"))
+	})
+
+	t.Run("escaped", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		New(bb).SetLevelKey(`level "name"`).Warning().Msg("")
+		ensureBytes(t, bb.Bytes(), []byte("{\"level \\\"name\\\"\":\"warning\"}\n"))
+	})
+
+	t.Run("custom time formatter", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		New(bb).SetTimeKey("ts").SetTimeFormatter(func(buf []byte) []byte {
+			return append(buf, `"when":"now",`...)
+		}).Warning().Msg("")
+		ensureBytes(t, bb.Bytes(), []byte("{\"when\":\"now\",\"level\":\"warning\"}\n"))
+	})
+
+	t.Run("encoder", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		log := NewWithEncoder(bb, LogfmtEncoder{}).SetTimeFormatter(counterTime()).
+			SetTimeKey("ts").SetLevelKey("severity").SetMessageKey("msg").SetErrorKey("err")
+		branch := log.With().String("module", "server").Logger()
+
+		log.Warning().Err(io.EOF).Msg("done")
+		_, _ = branch.NewWriter(Warning).Write([]byte("line"))
+
+		ensureBytes(t, bb.Bytes(), []byte(""+
+			"ts=1 severity=warning err=EOF msg=done\n"+
+			"ts=2 severity=warning module=server msg=line\n"))
+	})
+
+	t.Run("encoder escaped", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		NewWithEncoder(bb, LogfmtEncoder{}).SetLevelKey("log level").SetMessageKey(`"msg"`).
+			Warning().Msg("done")
+		ensureBytes(t, bb.Bytes(), []byte("log_level=warning _msg_=done\n"))
+	})
+
+	t.Run("console encoder", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		NewWithEncoder(bb, &ConsoleEncoder{}).SetLevelKey("severity").SetMessageKey("msg").
+			Warning().Msg("done")
+		ensureBytes(t, bb.Bytes(), []byte("WARNING done\n"))
+	})
+
+	t.Run("dedup", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		dw := NewDedupWriter(bb, time.Minute).SetTimeKey("ts")
+		log := New(dw).SetTimeFormatter(counterTime()).SetTimeKey("ts")
+
+		log.Warning().Msg("cannot connect")
+		log.Warning().Msg("cannot connect")
+		ensureBytes(t, bb.Bytes(), []byte("{\"ts\":1,\"level\":\"warning\",\"message\":\"cannot connect\"}\n"))
+		ensureError(t, dw.Close())
+	})
+
+	t.Run("allocations", func(t *testing.T) {
+		log := New(io.Discard).SetInfo().SetTimeFormatter(TimeUnix).
+			SetTimeKey("@timestamp").SetLevelKey("severity").SetMessageKey("msg").SetErrorKey("err")
+		err := errors.New("cannot connect")
+		emit := func() { log.Info().Err(err).Msg("retrying") }
+		if got := testing.AllocsPerRun(100, emit); got != 0 {
+			t.Errorf("GOT: %v; WANT: %v", got, 0)
+		}
+	})
+}
+
+func TestRenameProperty(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  string
+		to   string
+		want string
+	}{
+		{"same", `{"time":1,`, `"time":`, `{"time":1,`},
+		{"longer", `{"time":1,`, `"@timestamp":`, `{"@timestamp":1,`},
+		{"shorter", `{"time":"x",`, `"t":`, `{"t":"x",`},
+		{"other name", `{"when":1,`, `"ts":`, `{"when":1,`},
+		{"empty", `{`, `"ts":`, `{`},
+	}
+
+	for _, single := range tests {
+		t.Run(single.name, func(t *testing.T) {
+			buf := renameProperty([]byte(single.buf), 1, defaultTimeKey, single.to)
+			ensureBytes(t, buf, []byte(single.want))
+		})
+	}
+}
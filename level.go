@@ -1,33 +1,140 @@
 package gologs
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
 
-// Level type defines one of several possible log levels.
+// Level type defines one of several possible log levels. Levels with greater
+// values are more severe. The predefined levels are spaced apart so that
+// custom levels registered using RegisterLevel may be placed between them.
 type Level uint32
 
 const (
+	// Trace is for events that follow the detailed execution of a program,
+	// such as entering and leaving functions.
+	Trace Level = 10
+
 	// Debug is for events that might help a person understand the cause of a
 	// bug in a program.
-	Debug Level = iota
+	Debug Level = 20
 
 	// Verbose is for events that might help a person understand the state of a
 	// program.
-	Verbose
+	Verbose Level = 30
 
 	// Info is for events that annotate high level status of a program.
-	Info
+	Info Level = 40
 
 	// Warning is for events that indicate a possible problem with the
 	// program. Warning events should be investigated and corrected soon.
-	Warning
+	Warning Level = 50
 
 	// Error is for events that indicate a definite problem that might prevent
 	// normal program execution. Error events should be corrected immediately.
-	Error
+	Error Level = 60
+
+	// Fatal is for events that indicate a problem that prevents the program
+	// from continuing. After a Fatal event is written, the program exits.
+	Fatal Level = 70
+
+	// Panic is for events that indicate a problem that prevents the current
+	// goroutine from continuing. After a Panic event is written, the program
+	// panics with the event message.
+	Panic Level = 80
+)
+
+// customLevel describes a level registered using RegisterLevel.
+type customLevel struct {
+	name     string // name is returned by Level.String
+	label    string // label is the value of the level property
+	property string // property is the JSON encoded level property
+}
+
+// customLevels holds the map[Level]customLevel of registered levels. It is
+// replaced rather than modified so levels can be looked up without locking.
+var (
+	customLevels      atomic.Value
+	customLevelsMutex sync.Mutex
 )
 
+// RegisterLevel registers a custom level with the specified severity and
+// label, which is the value of the level property of its events. Events at a
+// custom level are created using Logger.WithLevel, are logged when the
+// Logger's level is at or below the custom level, and, like Error events, are
+// always logged when the custom level is above Error. Writers may also be
+// created to emit events at a custom level.
+//
+// It returns an error when the label is empty or already used by another
+// level, or when the severity is already used by another level or is zero,
+// which is reserved for the events created by Logger.Log that have no level.
+// Custom levels are meant to be registered once, while a program initializes.
+//
+//	const Notice = gologs.Level(45)
+//
+//	func init() {
+//	    if err := gologs.RegisterLevel(Notice, "notice"); err != nil {
+//	        panic(err)
+//	    }
+//	}
+func RegisterLevel(level Level, label string) error {
+	if label == "" {
+		return fmt.Errorf("cannot register level %d: empty label", uint32(level))
+	}
+	if level == 0 {
+		return fmt.Errorf("cannot register level %d: severity reserved for events without a level", uint32(level))
+	}
+
+	customLevelsMutex.Lock()
+	defer customLevelsMutex.Unlock()
+
+	previous, _ := customLevels.Load().(map[Level]customLevel)
+
+	if _, ok := previous[level]; ok || level.builtinLabel() != "" {
+		return fmt.Errorf("cannot register level %d: severity already registered", uint32(level))
+	}
+	for l := Trace; l <= Panic; l += 10 {
+		if strings.EqualFold(label, l.label()) {
+			return fmt.Errorf("cannot register level %d: label already registered: %q", uint32(level), label)
+		}
+	}
+	for _, cl := range previous {
+		if strings.EqualFold(label, cl.label) {
+			return fmt.Errorf("cannot register level %d: label already registered: %q", uint32(level), label)
+		}
+	}
+
+	levels := make(map[Level]customLevel, len(previous)+1)
+	for l, cl := range previous {
+		levels[l] = cl
+	}
+	levels[level] = customLevel{
+		name:     strings.ToUpper(label),
+		label:    label,
+		property: string(appendString(nil, "level", label)),
+	}
+	customLevels.Store(levels)
+	return nil
+}
+
+// lookupLevel returns the custom level registered with the specified
+// severity.
+func lookupLevel(level Level) (customLevel, bool) {
+	levels, _ := customLevels.Load().(map[Level]customLevel)
+	cl, ok := levels[level]
+	return cl, ok
+}
+
+// String returns the upper case name of the level. Levels that are neither
+// predefined nor registered are named by their severity, as in "LEVEL(42)".
 func (l Level) String() string {
 	switch l {
+	case Trace:
+		return "TRACE"
 	case Debug:
 		return "DEBUG"
 	case Verbose:
@@ -38,7 +145,180 @@ func (l Level) String() string {
 		return "WARNING"
 	case Error:
 		return "ERROR"
+	case Fatal:
+		return "FATAL"
+	case Panic:
+		return "PANIC"
+	}
+	if cl, ok := lookupLevel(l); ok {
+		return cl.name
+	}
+	return "LEVEL(" + strconv.FormatUint(uint64(l), 10) + ")"
+}
+
+// label returns the lower case name of the level, as used for the value of
+// the level property of an event.
+func (l Level) label() string {
+	if label := l.builtinLabel(); label != "" {
+		return label
+	}
+	if cl, ok := lookupLevel(l); ok {
+		return cl.label
+	}
+	return "level(" + strconv.FormatUint(uint64(l), 10) + ")"
+}
+
+// builtinLabel returns the label of a predefined level, or the empty string
+// for any other level.
+func (l Level) builtinLabel() string {
+	switch l {
+	case Trace:
+		return "trace"
+	case Debug:
+		return "debug"
+	case Verbose:
+		return "verbose"
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	case Fatal:
+		return "fatal"
+	case Panic:
+		return "panic"
+	}
+	return ""
+}
+
+// property returns the JSON encoded level property, including its trailing
+// comma, for a level that is not predefined.
+func (l Level) property() string {
+	if cl, ok := lookupLevel(l); ok {
+		return cl.property
+	}
+	return string(appendString(nil, "level", l.label()))
+}
+
+// ParseLevel returns the level named by s, which is case insensitive. It
+// accepts the names of the predefined levels, the aliases "warn" for Warning
+// and "err" for Error, the labels of custom levels registered using
+// RegisterLevel, and the decimal severities of predefined or registered levels,
+// such as "40" for Info. Other severities are rejected, so a mistaken number
+// cannot silently enable every level.
+//
+//	level, err := gologs.ParseLevel("WARN") // gologs.Warning
+func ParseLevel(s string) (Level, error) {
+	if level, ok := levelFromName([]byte(s)); ok {
+		return level, nil
+	}
+	if u, err := strconv.ParseUint(s, 10, 32); err == nil {
+		level := Level(u)
+		if _, ok := lookupLevel(level); ok || level.builtinLabel() != "" {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("cannot parse level: %q", s)
+}
+
+// levelFromName returns the level named by the case insensitive name, which
+// may be the name or alias of a predefined level, or the label of a custom
+// level.
+func levelFromName(name []byte) (Level, bool) {
+	// Predefined level names are short, so fold the case of the name into an
+	// array on the stack rather than allocating.
+	var arr [8]byte
+	if len(name) <= len(arr) {
+		for i, b := range name {
+			if 'A' <= b && b <= 'Z' {
+				b += 'a' - 'A'
+			}
+			arr[i] = b
+		}
+
+		switch string(arr[:len(name)]) {
+		case "trace":
+			return Trace, true
+		case "debug":
+			return Debug, true
+		case "verbose":
+			return Verbose, true
+		case "info":
+			return Info, true
+		case "warn", "warning":
+			return Warning, true
+		case "err", "error":
+			return Error, true
+		case "fatal":
+			return Fatal, true
+		case "panic":
+			return Panic, true
+		}
+	}
+
+	levels, _ := customLevels.Load().(map[Level]customLevel)
+	for level, cl := range levels {
+		if len(cl.label) == len(name) && strings.EqualFold(cl.label, string(name)) {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
+// MarshalText returns the label of the level, such as "warning", which is
+// the same value used for the level property of events. It implements the
+// encoding.TextMarshaler interface.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.label()), nil
+}
+
+// UnmarshalText sets the level to the level named by text, using the same
+// rules as ParseLevel. It implements the encoding.TextUnmarshaler interface,
+// allowing levels to be read from configuration files.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// Set sets the level to the level named by s, using the same rules as
+// ParseLevel. Along with String, it implements the flag.Value interface.
+//
+//	level := gologs.Info
+//	flag.Var(&level, "level", "log level: trace, debug, verbose, info, warning, or error")
+//	flag.Parse()
+//	log.SetLevel(level)
+func (l *Level) Set(s string) error {
+	return l.UnmarshalText([]byte(s))
+}
+
+// LevelEnv is the conventional name of the environment variable used to
+// configure the level of a Logger by Logger.SetLevelFromEnv.
+const LevelEnv = "GOLOGS_LEVEL"
+
+// SetLevelFromEnv changes the Logger's level to the level named by the
+// environment variable key, using the same rules as ParseLevel. When the
+// environment variable is not set or is empty, the Logger's level is not
+// changed. When it cannot be parsed, the Logger's level is not changed and
+// an error is returned.
+//
+//	log := gologs.New(os.Stderr).SetInfo()
+//	if err := log.SetLevelFromEnv(gologs.LevelEnv); err != nil {
+//	    log.Warning().Err(err).Msg("ignoring environment variable")
+//	}
+func (log *Logger) SetLevelFromEnv(key string) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	level, err := ParseLevel(value)
+	if err != nil {
+		return fmt.Errorf("cannot set level from %s: %w", key, err)
 	}
-	// NOT REACHED
-	panic(fmt.Sprintf("invalid log level: %d", uint32(l)))
+	log.SetLevel(level)
+	return nil
 }
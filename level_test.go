@@ -0,0 +1,238 @@
+package gologs
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"testing"
+)
+
+// Custom levels are registered once for the package tests, because the
+// registry is shared by every Logger.
+const (
+	testNotice = Level(45)
+	testAudit  = Level(65)
+)
+
+func init() {
+	if err := RegisterLevel(testNotice, "notice"); err != nil {
+		panic(err)
+	}
+	if err := RegisterLevel(testAudit, "audit"); err != nil {
+		panic(err)
+	}
+}
+
+func TestLevelString(t *testing.T) {
+	tests := []struct {
+		level Level
+		want  string
+	}{
+		{Trace, "TRACE"},
+		{Debug, "DEBUG"},
+		{Error, "ERROR"},
+		{testNotice, "NOTICE"},
+		{Level(42), "LEVEL(42)"},
+	}
+
+	for _, single := range tests {
+		if got, want := single.level.String(), single.want; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	t.Run("empty label", func(t *testing.T) {
+		ensureError(t, RegisterLevel(Level(46), ""), "empty label")
+	})
+	t.Run("zero severity", func(t *testing.T) {
+		ensureError(t, RegisterLevel(Level(0), "none"), "severity reserved")
+	})
+	t.Run("predefined severity", func(t *testing.T) {
+		ensureError(t, RegisterLevel(Info, "information"), "severity already registered")
+	})
+	t.Run("custom severity", func(t *testing.T) {
+		ensureError(t, RegisterLevel(testNotice, "note"), "severity already registered")
+	})
+	t.Run("predefined label", func(t *testing.T) {
+		ensureError(t, RegisterLevel(Level(46), "Warning"), "label already registered")
+	})
+	t.Run("custom label", func(t *testing.T) {
+		ensureError(t, RegisterLevel(Level(46), "NOTICE"), "label already registered")
+	})
+}
+
+func TestCustomLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		call func(*Logger)
+	}{
+		{
+			"below threshold should not log",
+			"",
+			func(l *Logger) {
+				l.SetWarning()
+				l.WithLevel(testNotice).Msg("ignored")
+			},
+		},
+		{
+			"at threshold should log",
+			"{\"level\":\"notice\",\"module\":\"auth\",\"user\":\"alice\",\"message\":\"password changed\"}\n",
+			func(l *Logger) {
+				l = l.With().String("module", "auth").Logger().SetLevel(testNotice)
+				l.WithLevel(testNotice).String("user", "alice").Msg("password changed")
+				l.Info().Msg("ignored")
+			},
+		},
+		{
+			"above error always logs",
+			"{\"level\":\"audit\",\"message\":\"logged\"}\n",
+			func(l *Logger) {
+				l.SetLevel(Level(1000))
+				l.WithLevel(testAudit).Msg("logged")
+			},
+		},
+		{
+			"predefined level",
+			"{\"level\":\"trace\",\"message\":\"logged\"}\n",
+			func(l *Logger) {
+				l.SetTrace()
+				l.WithLevel(Trace).Msg("logged")
+			},
+		},
+		{
+			"unregistered level",
+			"{\"level\":\"level(42)\",\"message\":\"logged\"}\n",
+			func(l *Logger) {
+				l.SetInfo()
+				l.WithLevel(Level(42)).Msg("logged")
+			},
+		},
+		{
+			"writer emits custom level",
+			"{\"level\":\"notice\",\"message\":\"written\"}\n",
+			func(l *Logger) {
+				w := l.SetInfo().NewWriter(testNotice)
+				_, _ = w.Write([]byte("written"))
+				w.SetLevel(Warning)
+				_, _ = w.Write([]byte("ignored"))
+			},
+		},
+	}
+
+	for _, single := range tests {
+		t.Run(single.name, func(t *testing.T) {
+			bb := new(bytes.Buffer)
+			single.call(New(bb))
+			ensureBytes(t, bb.Bytes(), []byte(single.want))
+		})
+	}
+
+	t.Run("encoders", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		NewWithEncoder(bb, LogfmtEncoder{}).SetInfo().WithLevel(testNotice).Msg("logfmt")
+		NewWithEncoder(bb, &ConsoleEncoder{}).SetInfo().WithLevel(testNotice).Msg("console")
+		ensureBytes(t, bb.Bytes(), []byte("level=notice message=logfmt\nNOTICE  console\n"))
+	})
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Level
+		err   string
+	}{
+		{"trace", Trace, ""},
+		{"DEBUG", Debug, ""},
+		{"Verbose", Verbose, ""},
+		{"info", Info, ""},
+		{"warn", Warning, ""},
+		{"WARNING", Warning, ""},
+		{"err", Error, ""},
+		{"error", Error, ""},
+		{"fatal", Fatal, ""},
+		{"panic", Panic, ""},
+		{"Notice", testNotice, ""},
+		{"45", testNotice, ""},
+		{"40", Info, ""},
+		{"7", 0, "cannot parse level"},
+		{"0", 0, "cannot parse level"},
+		{"", 0, "cannot parse level"},
+		{"verbosely", 0, "cannot parse level"},
+		{"-1", 0, "cannot parse level"},
+	}
+
+	for _, single := range tests {
+		t.Run(single.input, func(t *testing.T) {
+			got, err := ParseLevel(single.input)
+			ensureError(t, err, single.err)
+			if got != single.want {
+				t.Errorf("GOT: %v; WANT: %v", got, single.want)
+			}
+		})
+	}
+}
+
+func TestLevelText(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		var config struct {
+			Levels []Level `json:"levels"`
+		}
+		if err := json.Unmarshal([]byte(`{"levels":["WARN","notice","trace"]}`), &config); err != nil {
+			t.Fatal(err)
+		}
+		buf, err := json.Marshal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ensureBytes(t, buf, []byte(`{"levels":["warning","notice","trace"]}`))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		level := Info
+		ensureError(t, level.UnmarshalText([]byte("loud")), "cannot parse level")
+		if got, want := level, Info; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		level := Info
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.Var(&level, "level", "log level")
+
+		if err := fs.Parse([]string{"-level", "Debug"}); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := level, Debug; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureError(t, fs.Parse([]string{"-level", "loud"}), "cannot parse level")
+	})
+}
+
+func TestSetLevelFromEnv(t *testing.T) {
+	log := New(new(bytes.Buffer)).SetInfo()
+
+	t.Setenv(LevelEnv, "")
+	ensureError(t, log.SetLevelFromEnv(LevelEnv))
+	if got, want := log.Enabled(Verbose), false; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+
+	t.Setenv(LevelEnv, "verbose")
+	ensureError(t, log.SetLevelFromEnv(LevelEnv))
+	if got, want := log.Enabled(Verbose), true; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+
+	t.Setenv(LevelEnv, "loud")
+	ensureError(t, log.SetLevelFromEnv(LevelEnv), "cannot set level from GOLOGS_LEVEL")
+	if got, want := log.Enabled(Verbose), true; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+}
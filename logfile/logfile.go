@@ -0,0 +1,397 @@
+// Package logfile provides an io.Writer that writes log events to a file,
+// rotating the file when it grows too large or becomes too old, optionally
+// compressing rotated files, and removing rotated files that are no longer
+// needed.
+//
+//	lf, err := logfile.Open("/var/log/service/service.log")
+//	if err != nil {
+//	    panic(err)
+//	}
+//	lf.SetMaxSize(100 << 20).SetInterval(24 * time.Hour).SetCompress(true).SetMaxCount(7)
+//	defer lf.Close()
+//	log := gologs.New(lf)
+//
+// When a file is rotated, it is renamed by appending the time of its
+// rotation to its name, as in "service.log.20220806T151404.000000000", and a
+// new file is created using the original name. When compression is enabled,
+// rotated files are compressed in the background, and ".gz" is appended to
+// their names.
+//
+// A Writer also supports external rotation by programs like logrotate: after
+// such a program renames the file, invoking Reopen causes the Writer to
+// create a new file using the original name. The gologs.HandleSignals
+// function invokes Reopen when the program receives SIGHUP.
+package logfile
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timestampLayout formats the time of rotation appended to the names of
+// rotated files, which sorts in chronological order.
+const timestampLayout = "20060102T150405.000000000"
+
+// compressSuffix is appended to the names of compressed rotated files.
+const compressSuffix = ".gz"
+
+// rotateRetry is how long a Writer waits after failing to rotate its file
+// before it attempts to rotate the file again.
+const rotateRetry = time.Minute
+
+// now returns the current time, and is replaced by tests.
+var now = time.Now
+
+// Writer is an io.Writer that writes to a file, which it rotates according to
+// its configuration. It is safe for concurrent use.
+type Writer struct {
+	pathname string
+	mode     os.FileMode
+	maxSize  int64         // maxSize is zero when not rotating by size
+	interval time.Duration // interval is zero when not rotating by time
+	compress bool
+	maxAge   time.Duration // maxAge is zero when not removing by age
+	maxCount int           // maxCount is zero when not removing by count
+
+	file   *os.File
+	size   int64     // size is the number of bytes in file
+	rotate time.Time // rotate is when file is rotated by time
+	retry  time.Time // retry is when rotation is attempted again after it failed
+	mutex  sync.Mutex
+	mill   sync.Mutex     // mill serializes compression and removal of rotated files
+	wg     sync.WaitGroup // wg tracks background compression and removal
+}
+
+// Open returns a new Writer that appends to the file at pathname, creating
+// the file and its directory when necessary. By default, the Writer never
+// rotates the file; use its Set methods to configure rotation.
+func Open(pathname string) (*Writer, error) {
+	w := &Writer{pathname: pathname, mode: 0644}
+	if err := os.MkdirAll(filepath.Dir(pathname), 0755); err != nil {
+		return nil, fmt.Errorf("cannot open log file: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// SetMaxSize causes the file to be rotated before a write would make it
+// larger than size bytes. A size of zero disables rotation by size. A single
+// write larger than size is written to a new file by itself.
+func (w *Writer) SetMaxSize(size int64) *Writer {
+	w.mutex.Lock()
+	w.maxSize = size
+	w.mutex.Unlock()
+	return w
+}
+
+// SetInterval causes the file to be rotated at each multiple of interval
+// since the zero time, so an interval of 24 hours rotates the file at
+// midnight UTC. An interval of zero disables rotation by time.
+func (w *Writer) SetInterval(interval time.Duration) *Writer {
+	w.mutex.Lock()
+	w.interval = interval
+	w.setRotate()
+	w.mutex.Unlock()
+	return w
+}
+
+// SetCompress causes rotated files to be compressed using gzip.
+func (w *Writer) SetCompress(compress bool) *Writer {
+	w.mutex.Lock()
+	w.compress = compress
+	w.mutex.Unlock()
+	return w
+}
+
+// SetMaxAge causes rotated files to be removed once they were rotated longer
+// than age ago. An age of zero disables removal by age.
+func (w *Writer) SetMaxAge(age time.Duration) *Writer {
+	w.mutex.Lock()
+	w.maxAge = age
+	w.mutex.Unlock()
+	return w
+}
+
+// SetMaxCount causes all but the count most recently rotated files to be
+// removed. A count of zero disables removal by count.
+func (w *Writer) SetMaxCount(count int) *Writer {
+	w.mutex.Lock()
+	w.maxCount = count
+	w.mutex.Unlock()
+	return w
+}
+
+// Write writes buf to the file, first rotating the file when required by the
+// Writer's configuration.
+//
+// When the file cannot be closed or renamed while rotating it, the file at
+// the original pathname is opened again, buf is appended to it, and the
+// rotation error is returned along with the number of bytes written, so the
+// event is not lost. Rotation is not attempted again until a minute later.
+func (w *Writer) Write(buf []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return 0, fmt.Errorf("cannot write log file: closed")
+	}
+	var rerr error
+	if w.shouldRotate(int64(len(buf))) {
+		if rerr = w.rotateFile(); rerr != nil && w.file == nil {
+			return 0, rerr
+		}
+	}
+	n, err := w.file.Write(buf)
+	w.size += int64(n)
+	if err == nil {
+		err = rerr
+	}
+	return n, err
+}
+
+// Rotate rotates the file regardless of the Writer's configuration.
+func (w *Writer) Rotate() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return fmt.Errorf("cannot rotate log file: closed")
+	}
+	return w.rotateFile()
+}
+
+// Reopen closes the file and opens the file at the original pathname, which
+// creates a new file when the previous file was renamed by another program.
+// The file is opened even when closing the previous file fails, in which case
+// the error from closing it is returned.
+func (w *Writer) Reopen() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return fmt.Errorf("cannot reopen log file: closed")
+	}
+	err := w.file.Close()
+	w.file = nil
+	if err2 := w.open(); err2 != nil {
+		return err2
+	}
+	if err != nil {
+		return fmt.Errorf("cannot reopen log file: %w", err)
+	}
+	return nil
+}
+
+// Sync commits the contents of the file to stable storage.
+func (w *Writer) Sync() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return fmt.Errorf("cannot sync log file: closed")
+	}
+	return w.file.Sync()
+}
+
+// Close closes the file, and waits for the compression and removal of any
+// rotated files to complete.
+func (w *Writer) Close() error {
+	w.mutex.Lock()
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.mutex.Unlock()
+
+	w.wg.Wait()
+	return err
+}
+
+// open opens the file at the original pathname for appending. It must be
+// invoked while the Writer is locked.
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.pathname, os.O_WRONLY|os.O_APPEND|os.O_CREATE, w.mode)
+	if err != nil {
+		return fmt.Errorf("cannot open log file: %w", err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("cannot open log file: %w", err)
+	}
+	w.file = f
+	w.size = fi.Size()
+	w.setRotate()
+	return nil
+}
+
+// setRotate sets the next time the file is rotated by time. It must be
+// invoked while the Writer is locked.
+func (w *Writer) setRotate() {
+	if w.interval > 0 {
+		w.rotate = now().Truncate(w.interval).Add(w.interval)
+	}
+}
+
+// shouldRotate returns true when the file must be rotated before writing n
+// bytes to it. It must be invoked while the Writer is locked.
+func (w *Writer) shouldRotate(n int64) bool {
+	if w.size == 0 {
+		// Never rotate an empty file, but do not rotate it as soon as it is
+		// no longer empty either.
+		if w.interval > 0 && !now().Before(w.rotate) {
+			w.setRotate()
+		}
+		return false
+	}
+	if now().Before(w.retry) {
+		return false // a recent rotation failed
+	}
+	if w.maxSize > 0 && w.size+n > w.maxSize {
+		return true
+	}
+	return w.interval > 0 && !now().Before(w.rotate)
+}
+
+// rotateFile closes and renames the file, opens a new file at the original
+// pathname, then compresses and removes rotated files in the background. It
+// must be invoked while the Writer is locked.
+func (w *Writer) rotateFile() error {
+	if err := w.file.Close(); err != nil {
+		w.file = nil
+		return w.rotateFailed(err)
+	}
+	w.file = nil
+
+	rotated := w.pathname + "." + now().UTC().Format(timestampLayout)
+	if err := os.Rename(w.pathname, rotated); err != nil {
+		return w.rotateFailed(err)
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	var cutoff time.Time
+	if w.maxAge > 0 {
+		cutoff = now().Add(-w.maxAge)
+	}
+	compress, maxCount := w.compress, w.maxCount
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.mill.Lock()
+		defer w.mill.Unlock()
+
+		if compress {
+			_ = compressFile(rotated)
+		}
+		_ = w.removeRotated(cutoff, maxCount)
+	}()
+	return nil
+}
+
+// rotateFailed opens the file at the original pathname again after rotating
+// it failed with err, so events continue to be appended to it rather than
+// lost, and waits before attempting to rotate it again. It must be invoked
+// while the Writer is locked.
+func (w *Writer) rotateFailed(err error) error {
+	if err2 := w.open(); err2 != nil {
+		return err2
+	}
+	w.retry = now().Add(rotateRetry)
+	return fmt.Errorf("cannot rotate log file: %w", err)
+}
+
+// compressFile compresses the file at pathname into a new file with the
+// compression suffix appended to its name, then removes the original file.
+func compressFile(pathname string) error {
+	src, err := os.Open(pathname)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(pathname+compressSuffix, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err == nil {
+		err = zw.Close()
+	}
+	if err2 := dst.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		_ = os.Remove(pathname + compressSuffix)
+		return err
+	}
+	return os.Remove(pathname)
+}
+
+// removeRotated removes files rotated before cutoff, unless it is the zero
+// time, and all but the maxCount most recently rotated files, unless it is
+// zero.
+func (w *Writer) removeRotated(cutoff time.Time, maxCount int) error {
+	if cutoff.IsZero() && maxCount <= 0 {
+		return nil
+	}
+
+	rotated, err := w.rotatedFiles()
+	if err != nil {
+		return err
+	}
+
+	// Files are sorted from most to least recently rotated.
+	for i, rf := range rotated {
+		if (maxCount > 0 && i >= maxCount) || rf.when.Before(cutoff) {
+			if err2 := os.Remove(rf.pathname); err == nil {
+				err = err2
+			}
+		}
+	}
+	return err
+}
+
+// rotatedFile is a file that was rotated by a Writer.
+type rotatedFile struct {
+	pathname string
+	when     time.Time
+}
+
+// rotatedFiles returns the files rotated from the Writer's pathname, sorted
+// from most to least recently rotated.
+func (w *Writer) rotatedFiles() ([]rotatedFile, error) {
+	dir, base := filepath.Split(w.pathname)
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := base + "."
+	var rotated []rotatedFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		when, err := time.Parse(timestampLayout, strings.TrimSuffix(name[len(prefix):], compressSuffix))
+		if err != nil {
+			continue // not a rotated file
+		}
+		rotated = append(rotated, rotatedFile{pathname: filepath.Join(dir, name), when: when})
+	}
+	sort.Slice(rotated, func(i, j int) bool { return rotated[i].when.After(rotated[j].when) })
+	return rotated, nil
+}
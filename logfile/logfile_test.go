@@ -0,0 +1,306 @@
+package logfile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/karrick/gologs"
+)
+
+// setNow replaces the current time used by the package for the duration of
+// the test, returning a function that advances it.
+func setNow(tb testing.TB) func(time.Duration) {
+	tb.Helper()
+	t := time.Date(2022, 8, 6, 15, 14, 4, 0, time.UTC)
+	previous := now
+	now = func() time.Time { return t }
+	tb.Cleanup(func() { now = previous })
+	return func(d time.Duration) { t = t.Add(d) }
+}
+
+// listDir returns the sorted names of the files in dir.
+func listDir(tb testing.TB, dir string) []string {
+	tb.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func ensureFile(tb testing.TB, pathname, want string) {
+	tb.Helper()
+	buf, err := os.ReadFile(pathname)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if filepath.Ext(pathname) == compressSuffix {
+		zr, err := gzip.NewReader(bytes.NewReader(buf))
+		if err != nil {
+			tb.Fatal(err)
+		}
+		if buf, err = io.ReadAll(zr); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	if got := string(buf); got != want {
+		tb.Errorf("\nGOT:  %q\nWANT: %q\n", got, want)
+	}
+}
+
+func ensureNames(tb testing.TB, got []string, want ...string) {
+	tb.Helper()
+	if len(got) != len(want) {
+		tb.Fatalf("GOT: %v; WANT: %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			tb.Fatalf("GOT: %v; WANT: %v", got, want)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	t.Run("rotates by size", func(t *testing.T) {
+		advance := setNow(t)
+		dir := t.TempDir()
+		lf, err := Open(filepath.Join(dir, "logs", "app.log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lf.SetMaxSize(8)
+
+		for _, s := range []string{"one\n", "two\n", "three\n", "a much longer line\n"} {
+			if _, err := lf.Write([]byte(s)); err != nil {
+				t.Fatal(err)
+			}
+			advance(time.Second)
+		}
+		if err := lf.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		dir = filepath.Join(dir, "logs")
+		ensureNames(t, listDir(t, dir),
+			"app.log",
+			"app.log.20220806T151406.000000000",
+			"app.log.20220806T151407.000000000",
+		)
+		ensureFile(t, filepath.Join(dir, "app.log.20220806T151406.000000000"), "one\ntwo\n")
+		ensureFile(t, filepath.Join(dir, "app.log.20220806T151407.000000000"), "three\n")
+		ensureFile(t, filepath.Join(dir, "app.log"), "a much longer line\n")
+	})
+
+	t.Run("rename fails", func(t *testing.T) {
+		advance := setNow(t)
+		dir := t.TempDir()
+		pathname := filepath.Join(dir, "app.log")
+		lf, err := Open(pathname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lf.SetMaxSize(8)
+
+		// A non-empty directory where the file would be renamed prevents
+		// rotation.
+		blocker := pathname + ".20220806T151404.000000000"
+		if err := os.MkdirAll(filepath.Join(blocker, "x"), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := lf.Write([]byte("one\n")); err != nil {
+			t.Fatal(err)
+		}
+		n, err := lf.Write([]byte("two-two\n"))
+		if err == nil || !strings.Contains(err.Error(), "cannot rotate log file") {
+			t.Errorf("GOT: %v; WANT: %v", err, "cannot rotate log file")
+		}
+		if got, want := n, 8; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		// Rotation is not attempted again until the retry delay elapses.
+		advance(time.Second)
+		if _, err := lf.Write([]byte("three\n")); err != nil {
+			t.Fatal(err)
+		}
+		advance(rotateRetry)
+		if err := os.RemoveAll(blocker); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := lf.Write([]byte("four\n")); err != nil {
+			t.Fatal(err)
+		}
+		if err := lf.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		ensureNames(t, listDir(t, dir), "app.log", "app.log.20220806T151505.000000000")
+		ensureFile(t, filepath.Join(dir, "app.log.20220806T151505.000000000"), "one\ntwo-two\nthree\n")
+		ensureFile(t, pathname, "four\n")
+	})
+
+	t.Run("close fails", func(t *testing.T) {
+		advance := setNow(t)
+		dir := t.TempDir()
+		pathname := filepath.Join(dir, "app.log")
+		lf, err := Open(pathname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lf.SetMaxSize(8)
+
+		if _, err := lf.Write([]byte("one\n")); err != nil {
+			t.Fatal(err)
+		}
+		// Closing the file behind the Writer makes its own close fail.
+		_ = lf.file.Close()
+		n, err := lf.Write([]byte("two-two\n"))
+		if err == nil || !strings.Contains(err.Error(), "cannot rotate log file") {
+			t.Errorf("GOT: %v; WANT: %v", err, "cannot rotate log file")
+		}
+		if got, want := n, 8; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		// Rotation is not attempted again until the retry delay elapses.
+		advance(time.Second)
+		if _, err := lf.Write([]byte("three\n")); err != nil {
+			t.Fatal(err)
+		}
+
+		// Reopening also opens the file again when closing it fails.
+		_ = lf.file.Close()
+		if err := lf.Reopen(); err == nil || !strings.Contains(err.Error(), "cannot reopen log file") {
+			t.Errorf("GOT: %v; WANT: %v", err, "cannot reopen log file")
+		}
+		if _, err := lf.Write([]byte("four\n")); err != nil {
+			t.Fatal(err)
+		}
+		if err := lf.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		ensureNames(t, listDir(t, dir), "app.log")
+		ensureFile(t, pathname, "one\ntwo-two\nthree\nfour\n")
+	})
+
+	t.Run("rotates by time and compresses", func(t *testing.T) {
+		advance := setNow(t)
+		dir := t.TempDir()
+		pathname := filepath.Join(dir, "app.log")
+		lf, err := Open(pathname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lf.SetInterval(time.Hour).SetCompress(true)
+
+		_, _ = lf.Write([]byte("first\n"))
+		advance(30 * time.Minute)
+		_, _ = lf.Write([]byte("second\n"))
+		advance(30 * time.Minute) // 16:14:04
+		_, _ = lf.Write([]byte("third\n"))
+		if err := lf.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		ensureNames(t, listDir(t, dir), "app.log", "app.log.20220806T161404.000000000.gz")
+		ensureFile(t, filepath.Join(dir, "app.log.20220806T161404.000000000.gz"), "first\nsecond\n")
+		ensureFile(t, pathname, "third\n")
+	})
+
+	t.Run("removes by count and age", func(t *testing.T) {
+		advance := setNow(t)
+		dir := t.TempDir()
+		pathname := filepath.Join(dir, "app.log")
+
+		// Files that are not rotated files are left alone.
+		if err := os.WriteFile(filepath.Join(dir, "app.log.notes"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		lf, err := Open(pathname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lf.SetMaxCount(2).SetMaxAge(90 * time.Minute)
+
+		for _, s := range []string{"1\n", "2\n", "3\n", "4\n"} {
+			_, _ = lf.Write([]byte(s))
+			if err := lf.Rotate(); err != nil {
+				t.Fatal(err)
+			}
+			advance(time.Minute)
+		}
+		if err := lf.Close(); err != nil {
+			t.Fatal(err)
+		}
+		ensureNames(t, listDir(t, dir),
+			"app.log",
+			"app.log.20220806T151604.000000000",
+			"app.log.20220806T151704.000000000",
+			"app.log.notes",
+		)
+
+		// Rotating two hours later removes the rotated files that are too
+		// old.
+		advance(2 * time.Hour)
+		if lf, err = Open(pathname); err != nil {
+			t.Fatal(err)
+		}
+		lf.SetMaxAge(90 * time.Minute)
+		_, _ = lf.Write([]byte("5\n"))
+		if err := lf.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+		if err := lf.Close(); err != nil {
+			t.Fatal(err)
+		}
+		ensureNames(t, listDir(t, dir),
+			"app.log",
+			"app.log.20220806T171804.000000000",
+			"app.log.notes",
+		)
+	})
+
+	t.Run("reopen after external rotation", func(t *testing.T) {
+		dir := t.TempDir()
+		pathname := filepath.Join(dir, "app.log")
+		lf, err := Open(pathname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		log := gologs.New(lf).SetInfo()
+
+		log.Info().Msg("before")
+		if err := os.Rename(pathname, pathname+".1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := log.Reopen(); err != nil {
+			t.Fatal(err)
+		}
+		log.Info().Msg("after")
+		if err := lf.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		ensureFile(t, pathname+".1", "{\"level\":\"info\",\"message\":\"before\"}\n")
+		ensureFile(t, pathname, "{\"level\":\"info\",\"message\":\"after\"}\n")
+
+		if _, err := lf.Write([]byte("closed")); err == nil {
+			t.Errorf("GOT: %v; WANT: %v", err, "error")
+		}
+	})
+}
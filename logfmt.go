@@ -0,0 +1,111 @@
+package gologs
+
+// LogfmtEncoder is an Encoder that formats each event as a single line of
+// logfmt, a sequence of space separated name=value pairs. String values are
+// only quoted when they are empty, or contain whitespace, double quotes, equal
+// signs, or characters that require escaping. Nested objects and arrays are
+// written as quoted JSON.
+//
+//	log := gologs.NewWithEncoder(os.Stderr, gologs.LogfmtEncoder{})
+//	log.Warning().String("pathname", "/tmp/foo").Int("size", 42).Msg("cannot open")
+//	// Output:
+//	// level=warning pathname=/tmp/foo size=42 message="cannot open"
+type LogfmtEncoder struct{}
+
+// Begin returns buf unchanged because logfmt lines have no prefix.
+func (LogfmtEncoder) Begin(buf []byte) []byte {
+	return buf
+}
+
+// Level appends the level of the event as a name=level pair, where name is
+// "level" unless changed using Logger.SetLevelKey.
+func (LogfmtEncoder) Level(buf []byte, name string, level Level) []byte {
+	buf = appendLogfmtName(buf, name)
+	buf = append(buf, level.label()...)
+	return append(buf, ' ')
+}
+
+// Time re-encodes the time property as a name=value pair.
+func (le LogfmtEncoder) Time(buf []byte, start int) []byte {
+	return le.Property(buf, start)
+}
+
+// Property re-encodes the property as a name=value pair.
+func (LogfmtEncoder) Property(buf []byte, start int) []byte {
+	end := len(buf)
+	name, value := splitProperty(buf[start:end])
+
+	// Names cannot be quoted, so replace characters that would make the
+	// pair ambiguous.
+	for _, b := range name {
+		if b <= ' ' || b == '=' || b == '"' {
+			b = '_'
+		}
+		buf = append(buf, b)
+	}
+	buf = append(buf, '=')
+
+	switch {
+	case len(value) == 0:
+		// NOT REACHED
+	case value[0] == '"':
+		if s, ok := unquotedString(value, "="); ok {
+			buf = append(buf, s...)
+		} else {
+			// The JSON encoded string is already a valid quoted logfmt
+			// value.
+			buf = append(buf, value...)
+		}
+	case value[0] == '{' || value[0] == '[':
+		buf = appendLogfmtQuoted(buf, value)
+	default:
+		// Numbers, and the true, false, and null literals.
+		buf = append(buf, value...)
+	}
+
+	buf = append(buf, ' ')
+	return replaceTail(buf, start, end)
+}
+
+// End appends the message as a name=value pair when it is not empty, where
+// name is "message" unless changed using Logger.SetMessageKey, and terminates
+// the line.
+func (LogfmtEncoder) End(buf []byte, fields int, name, message string) []byte {
+	if message != "" {
+		buf = appendLogfmtName(buf, name)
+		start := len(buf)
+		buf = appendEncodedJSONFromString(buf, message)
+		if s, ok := unquotedString(buf[start:], "="); ok {
+			buf = buf[:start+copy(buf[start:], s)]
+		}
+	} else if len(buf) > 0 && buf[len(buf)-1] == ' ' {
+		buf = buf[:len(buf)-1]
+	}
+	return append(buf, '\n')
+}
+
+// appendLogfmtName appends name to buf followed by an equal sign, replacing
+// characters that would make the pair ambiguous, as Property does.
+func appendLogfmtName(buf []byte, name string) []byte {
+	for i := 0; i < len(name); i++ {
+		b := name[i]
+		if b <= ' ' || b == '=' || b == '"' {
+			b = '_'
+		}
+		buf = append(buf, b)
+	}
+	return append(buf, '=')
+}
+
+// appendLogfmtQuoted appends value to buf as a double quoted string,
+// escaping any double quote and backslash characters it contains.
+func appendLogfmtQuoted(buf, value []byte) []byte {
+	buf = append(buf, '"')
+	for _, b := range value {
+		if b == '"' || b == '\\' {
+			buf = append(buf, '\\')
+		}
+		buf = append(buf, b)
+	}
+	return append(buf, '"')
+}
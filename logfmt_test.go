@@ -0,0 +1,83 @@
+package gologs
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLogfmtEncoder(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		call func(*Logger)
+	}{
+		{
+			"message only",
+			"level=warning message=\"some message\"\n",
+			func(l *Logger) { l.Warning().Msg("some message") },
+		},
+		{
+			"bare message",
+			"level=warning message=started\n",
+			func(l *Logger) { l.Warning().Msg("started") },
+		},
+		{
+			"properties without message",
+			"level=error happy=true usage=42.3 age=42 eye-color=brown error=null\n",
+			func(l *Logger) {
+				l.Error().Bool("happy", true).Float("usage", 42.3).Int("age", 42).String("eye-color", "brown").Err(nil).Msg("")
+			},
+		},
+		{
+			"ambiguous strings are quoted",
+			"level=warning empty=\"\" space=\"a b\" equals=\"a=b\" quote=\"a\\\"b\" newline=\"a\\nb\"\n",
+			func(l *Logger) {
+				l.Warning().
+					String("empty", "").
+					String("space", "a b").
+					String("equals", "a=b").
+					String("quote", "a\"b").
+					String("newline", "a\nb").
+					Msg("")
+			},
+		},
+		{
+			"ambiguous names are replaced",
+			"level=warning a_b=1 c_d=2\n",
+			func(l *Logger) { l.Warning().Int("a b", 1).Int("c=d", 2).Msg("") },
+		},
+		{
+			"nested properties are quoted JSON",
+			"level=warning http=\"{\\\"method\\\":\\\"GET\\\"}\" ids=\"[1,2]\"\n",
+			func(l *Logger) {
+				l.Warning().
+					Object("http", func(o *Object) { o.String("method", "GET") }).
+					Array("ids", func(a *Array) { a.Int(1).Int(2) }).
+					Msg("")
+			},
+		},
+		{
+			"time and branch",
+			"time=1643776764 level=info module=signals pid=42 message=started\n",
+			func(l *Logger) {
+				l.SetTimeFormatter(func(buf []byte) []byte {
+					return appendInt(buf, "time", 1643776764)
+				})
+				l.With().String("module", "signals").Logger().SetInfo().Info().Int("pid", 42).Msg("started")
+			},
+		},
+		{
+			"writer",
+			"level=info message=\"line 1\\n\"\n",
+			func(l *Logger) { l.SetInfo().NewWriter(Info).Write([]byte("line 1\n")) },
+		},
+	}
+
+	for _, single := range tests {
+		t.Run(single.name, func(t *testing.T) {
+			bb := new(bytes.Buffer)
+			single.call(NewWithEncoder(bb, LogfmtEncoder{}))
+			ensureBytes(t, bb.Bytes(), []byte(single.want))
+		})
+	}
+}
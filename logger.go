@@ -10,11 +10,15 @@ import (
 // written using a single invocation of the Write method for the underlying
 // io.Writer.
 type Logger struct {
-	event   Event
-	branch  []byte       // branch holds potentially empty prefix of each log event
-	mutex   sync.RWMutex // mutex for copying branch
-	level   uint32
-	tracing bool
+	dropped  uint64   // dropped is the number of events dropped by the Sampler
+	counters Counters // counters are shared with the Writers created by the Logger
+	event    Event
+	branch   []byte            // branch holds potentially empty prefix of each log event
+	mutex    sync.RWMutex      // mutex for copying branch and for node
+	node     *registeredBranch // node is not nil while the Logger is in a Registry
+	sampler  atomic.Value      // sampler holds a sampler, whose Sampler may be nil
+	level    uint32
+	tracing  bool
 }
 
 // New returns a new Logger that writes log events to w.
@@ -24,14 +28,29 @@ type Logger struct {
 //
 //	log := gologs.New(os.Stdout).SetTimeFormatter(gologs.TimeUnix)
 func New(w io.Writer) *Logger {
+	return NewWithEncoder(w, nil)
+}
+
+// NewWithEncoder returns a new Logger that writes log events to w, after
+// converting each event using the specified Encoder. Every branch created
+// from the returned Logger, and every Writer created from those branches,
+// use the same Encoder. When encoder is nil, the Logger writes JSON events,
+// just like a Logger returned by New.
+//
+//	log := gologs.NewWithEncoder(os.Stderr, &gologs.ConsoleEncoder{Color: gologs.IsTerminal(os.Stderr)})
+func NewWithEncoder(w io.Writer, encoder Encoder) *Logger {
 	log := &Logger{
 		event: Event{
-			scratch: make([]byte, 1, 2048),
-			output:  &output{w: w},
+			scratch:           newScratch(encoder),
+			durationFormatter: DurationNanoseconds,
+			output:            newOutput(w),
+			encoder:           encoder,
+			keys:              defaultKeys,
 		},
 		level: uint32(Warning),
 	}
-	log.event.scratch[0] = '{'
+	log.event.prefix = len(log.event.scratch)
+	log.event.counters = &log.counters
 	return log
 }
 
@@ -42,26 +61,65 @@ func (log *Logger) SetWriter(w io.Writer) *Logger {
 	return log
 }
 
+// Reopen reopens the underlying io.Writer when it has a Reopen method, such as
+// a file writer that must reopen its file after an external program rotated
+// it, potentially blocking until any in progress log event has been written.
+// It returns nil when the underlying io.Writer has no Reopen method.
+func (log *Logger) Reopen() error {
+	return log.event.output.Reopen()
+}
+
 // SetLevel changes the Logger's level to the specified Level without
-// blocking.
+// blocking. When the Logger is in a Registry, the change explicitly overrides
+// any level it inherited from its parent, and cascades to its descendants
+// that have not explicitly overridden their own levels, potentially blocking
+// until the Registry is not being used by another goroutine.
 func (log *Logger) SetLevel(level Level) *Logger {
-	atomic.StoreUint32((*uint32)(&log.level), uint32(level))
+	log.mutex.RLock()
+	node := log.node
+	log.mutex.RUnlock()
+
+	if node == nil || !node.registry.setLevelOf(node, level) {
+		log.storeLevel(level)
+	}
 	return log
 }
 
-// SetDebug changes the Logger's level to Debug, which allows all events to be
+// storeLevel changes the Logger's level to the specified Level without
+// blocking.
+func (log *Logger) storeLevel(level Level) {
+	atomic.StoreUint32((*uint32)(&log.level), uint32(level))
+}
+
+// Level returns the Logger's current level without blocking.
+func (log *Logger) Level() Level {
+	return Level(atomic.LoadUint32((*uint32)(&log.level)))
+}
+
+// Tracing returns true when the Logger logs all events regardless of its
+// level, because it was created from an Intermediate with tracing enabled.
+func (log *Logger) Tracing() bool {
+	return log.tracing
+}
+
+// SetTrace changes the Logger's level to Trace, which allows all events to be
 // logged. The change is made without blocking.
+func (log *Logger) SetTrace() *Logger {
+	return log.SetLevel(Trace)
+}
+
+// SetDebug changes the Logger's level to Debug, which causes all Trace events
+// to be ignored, and all other events to be logged. The change is made
+// without blocking.
 func (log *Logger) SetDebug() *Logger {
-	atomic.StoreUint32((*uint32)(&log.level), uint32(Debug))
-	return log
+	return log.SetLevel(Debug)
 }
 
 // SetVerbose changes the Logger's level to Verbose, which causes all Debug
 // events to be ignored, and all Verbose, Info, Warning, and Error events to
 // be logged. The change is made without blocking.
 func (log *Logger) SetVerbose() *Logger {
-	atomic.StoreUint32((*uint32)(&log.level), uint32(Verbose))
-	return log
+	return log.SetLevel(Verbose)
 }
 
 // SetInfo changes the Logger's level to Info, which causes all Debug and
@@ -69,24 +127,21 @@ func (log *Logger) SetVerbose() *Logger {
 // logged. The change is made without blocking. The change is made without
 // blocking.
 func (log *Logger) SetInfo() *Logger {
-	atomic.StoreUint32((*uint32)(&log.level), uint32(Info))
-	return log
+	return log.SetLevel(Info)
 }
 
 // SetWarning changes the Logger's level to Warning, which causes all Debug,
 // Verbose, and Info events to be ignored, and all Warning, and Error events
 // to be logged. The change is made without blocking.
 func (log *Logger) SetWarning() *Logger {
-	atomic.StoreUint32((*uint32)(&log.level), uint32(Warning))
-	return log
+	return log.SetLevel(Warning)
 }
 
 // SetError changes the Logger's level to Error, which causes all Debug,
 // Verbose, Info, and Warning events to be ignored, and all Error events to be
 // logged. The change is made without blocking.
 func (log *Logger) SetError() *Logger {
-	atomic.StoreUint32((*uint32)(&log.level), uint32(Error))
-	return log
+	return log.SetLevel(Error)
 }
 
 // SetTimeFormatter updates the time formatting callback function that is
@@ -97,6 +152,147 @@ func (log *Logger) SetTimeFormatter(callback TimeFormatter) *Logger {
 	return log
 }
 
+// SetDurationFormatter updates the duration formatting callback function
+// that is invoked for every Duration property added to an event, potentially
+// blocking until any in progress log event has been written. Branches created
+// after this call inherit the new DurationFormatter.
+//
+//	log := gologs.New(os.Stdout).SetDurationFormatter(gologs.DurationSeconds)
+func (log *Logger) SetDurationFormatter(callback DurationFormatter) *Logger {
+	log.event.setDurationFormatter(callback)
+	return log
+}
+
+// SetTimeKey changes the name of the time property, which is "time" by
+// default, potentially blocking until any in progress log event has been
+// written. The property appended by the Logger's TimeFormatter is renamed
+// when it uses the default name, which is the case for every TimeFormatter
+// provided by this library. Branches and Writers created after this call
+// inherit the new name.
+//
+//	log := gologs.New(os.Stdout).SetTimeFormatter(gologs.TimeUnix).SetTimeKey("ts")
+func (log *Logger) SetTimeKey(key string) *Logger {
+	encoded := encodeKey(key)
+	log.event.setKeys(func(k *keys) { k.time = encoded })
+	return log
+}
+
+// SetLevelKey changes the name of the level property, which is "level" by
+// default, potentially blocking until any in progress log event has been
+// written. Branches and Writers created after this call inherit the new name.
+// A Logger that has an Encoder passes the name to its Encoder, which may
+// ignore it, as the ConsoleEncoder does.
+//
+//	log := gologs.New(os.Stdout).SetLevelKey("severity")
+func (log *Logger) SetLevelKey(key string) *Logger {
+	encoded := encodeKey(key)
+	log.event.setKeys(func(k *keys) { k.level, k.levelName = encoded, key })
+	return log
+}
+
+// SetMessageKey changes the name of the message property, which is "message"
+// by default, potentially blocking until any in progress log event has been
+// written. Branches and Writers created after this call inherit the new name.
+// A Logger that has an Encoder passes the name to its Encoder, which may
+// ignore it, as the ConsoleEncoder does.
+//
+//	log := gologs.New(os.Stdout).SetMessageKey("msg")
+func (log *Logger) SetMessageKey(key string) *Logger {
+	encoded := encodeKey(key)
+	log.event.setKeys(func(k *keys) { k.message, k.messageName = encoded, key })
+	return log
+}
+
+// SetErrorKey changes the name of the property written by Event.Err, which
+// is "error" by default, potentially blocking until any in progress log event
+// has been written. Branches and Writers created after this call inherit the
+// new name.
+//
+//	log := gologs.New(os.Stdout).SetErrorKey("err")
+func (log *Logger) SetErrorKey(key string) *Logger {
+	log.event.setKeys(func(k *keys) { k.error = key })
+	return log
+}
+
+// AddHook adds hook to the hooks invoked by Event.Msg for every event of the
+// Logger, after the hooks already added, potentially blocking until any in
+// progress log event has been written. Branches created after this call
+// inherit the hook.
+//
+//	log.AddHook(func(e *gologs.Event, level gologs.Level, message string) bool {
+//	    return !strings.HasPrefix(message, "health check")
+//	})
+func (log *Logger) AddHook(hook Hook) *Logger {
+	log.event.addHook(hook)
+	return log
+}
+
+// SetCounting enables or disables counting the events of the Logger by
+// level, without blocking. Branches created after this call inherit the
+// setting, but count their own events.
+func (log *Logger) SetCounting(enabled bool) *Logger {
+	log.counters.setEnabled(enabled)
+	return log
+}
+
+// Counters returns the Counters of the Logger, which only count events while
+// counting is enabled.
+func (log *Logger) Counters() *Counters {
+	return &log.counters
+}
+
+// SetSampler changes the Sampler that decides which events the Logger logs,
+// without blocking. A nil Sampler causes the Logger to log every event its
+// level allows. Branches created from the Logger after this call share the
+// Sampler, and therefore its budget.
+//
+//	log.SetSampler(gologs.NewIntervalSampler(time.Second, 10, 100))
+func (log *Logger) SetSampler(s Sampler) *Logger {
+	log.sampler.Store(sampler{s})
+	return log
+}
+
+// Dropped returns the number of events the Logger did not log because its
+// Sampler did not allow them.
+func (log *Logger) Dropped() uint64 {
+	return atomic.LoadUint64(&log.dropped)
+}
+
+// sample returns true when the Logger has no Sampler, or when its Sampler
+// allows an event at the specified level, and otherwise counts the event as
+// dropped. It does not block unless the Sampler blocks.
+func (log *Logger) sample(level Level) bool {
+	s, _ := log.sampler.Load().(sampler)
+	if s.Sampler == nil || s.Sample(level) {
+		return true
+	}
+	atomic.AddUint64(&log.dropped, 1)
+	return false
+}
+
+// enabled returns true when level is at or above the Logger's level, and
+// otherwise counts an event at level as gated. It does not block.
+func (log *Logger) enabled(level Level) bool {
+	if Level(atomic.LoadUint32((*uint32)(&log.level))) <= level {
+		return true
+	}
+	log.counters.gate(level)
+	return false
+}
+
+// samplerOf returns the Sampler of the Logger, or nil when it has none.
+func (log *Logger) samplerOf() Sampler {
+	s, _ := log.sampler.Load().(sampler)
+	return s.Sampler
+}
+
+// Enabled returns true when an event at the specified level would be logged
+// by the Logger, without blocking. Because Error events are always logged, it
+// always returns true for Error.
+func (log *Logger) Enabled(level Level) bool {
+	return level >= Error || log.tracing || Level(atomic.LoadUint32((*uint32)(&log.level))) <= level
+}
+
 // Log returns an Event to be formatted and sent to the Logger's underlying
 // io.Writer, regardless of the Logger's log level, and omitting the event log
 // level in the output.
@@ -104,11 +300,21 @@ func (log *Logger) Log() *Event {
 	return log.event.log(log.branch)
 }
 
+// Trace returns an Event to be formatted and sent to the Logger's underlying
+// io.Writer when the Logger's level is Trace. If the Logger's level is above
+// Trace, this method returns without blocking.
+func (log *Logger) Trace() *Event {
+	if log.tracing || log.enabled(Trace) && log.sample(Trace) {
+		return log.event.trace(log.branch)
+	}
+	return nil
+}
+
 // Debug returns an Event to be formatted and sent to the Logger's underlying
-// io.Writer when the Logger's level is Debug. If the Logger's level is above
-// Debug, this method returns without blocking.
+// io.Writer when the Logger's level is Trace or Debug. If the Logger's level
+// is above Debug, this method returns without blocking.
 func (log *Logger) Debug() *Event {
-	if log.tracing || Level(atomic.LoadUint32((*uint32)(&log.level))) <= Debug {
+	if log.tracing || log.enabled(Debug) && log.sample(Debug) {
 		return log.event.debug(log.branch)
 	}
 	return nil
@@ -118,7 +324,7 @@ func (log *Logger) Debug() *Event {
 // underlying io.Writer when the Logger's level is Debug or Verbose. If the
 // Logger's level is above Verbose, this method returns without blocking.
 func (log *Logger) Verbose() *Event {
-	if log.tracing || Level(atomic.LoadUint32((*uint32)(&log.level))) <= Verbose {
+	if log.tracing || log.enabled(Verbose) && log.sample(Verbose) {
 		return log.event.verbose(log.branch)
 	}
 	return nil
@@ -128,7 +334,7 @@ func (log *Logger) Verbose() *Event {
 // io.Writer when the Logger's level is Debug, Verbose, or Info. If the
 // Logger's level is above Info, this method returns without blocking.
 func (log *Logger) Info() *Event {
-	if log.tracing || Level(atomic.LoadUint32((*uint32)(&log.level))) <= Info {
+	if log.tracing || log.enabled(Info) && log.sample(Info) {
 		return log.event.info(log.branch)
 	}
 	return nil
@@ -139,20 +345,61 @@ func (log *Logger) Info() *Event {
 // Warning. If the Logger's level is above Warning, this method returns
 // without blocking.
 func (log *Logger) Warning() *Event {
-	if log.tracing || Level(atomic.LoadUint32((*uint32)(&log.level))) <= Warning {
+	if log.tracing || log.enabled(Warning) && log.sample(Warning) {
 		return log.event.warning(log.branch)
 	}
 	return nil
 }
 
 // Error returns an Event to be formatted and sent to the Logger's underlying
-// io.Writer.
+// io.Writer, unless the Logger's Sampler drops it.
 func (log *Logger) Error() *Event {
-	return log.event.error(log.branch)
+	if log.tracing || log.sample(Error) {
+		return log.event.error(log.branch)
+	}
+	return nil
+}
+
+// Fatal returns an Event to be formatted and sent to the Logger's underlying
+// io.Writer, regardless of the Logger's level. After the Event is written by
+// its Msg method, the underlying io.Writer is flushed when it has either a
+// Flush or a Sync method, the exit hooks registered using RegisterExitHook
+// are invoked, and the program exits with the code specified using
+// SetExitCode, which defaults to 1.
+//
+//	log.Fatal().Err(err).Msg("cannot open configuration")
+func (log *Logger) Fatal() *Event {
+	return log.event.fatal(log.branch)
+}
+
+// Panic returns an Event to be formatted and sent to the Logger's underlying
+// io.Writer, regardless of the Logger's level. After the Event is written by
+// its Msg method, Msg panics with the event message.
+func (log *Logger) Panic() *Event {
+	return log.event.panic(log.branch)
+}
+
+// WithLevel returns an Event to be formatted and sent to the Logger's
+// underlying io.Writer when the Logger would log an event at the specified
+// level, which may be a predefined level or a custom level registered using
+// RegisterLevel. If the Logger would not log the event, this method returns
+// without blocking. Events at the Fatal and Panic levels keep their
+// terminating behavior.
+//
+//	log.WithLevel(Notice).String("user", name).Msg("password changed")
+func (log *Logger) WithLevel(level Level) *Event {
+	if level >= Error || log.tracing || log.enabled(level) {
+		if log.tracing || level == Fatal || level == Panic || log.sample(level) {
+			return log.event.begin(level, log.branch)
+		}
+	}
+	return nil
 }
 
 // NewWriter creates an io.Writer that conveys all writes it receives to the
-// underlying io.Writer as individual log events.
+// underlying io.Writer as individual log events. Because writes to an
+// io.Writer should not terminate the program, a level above Error, such as
+// Fatal or Panic, is treated as Error.
 //
 //	func main() {
 //	    log := gologs.New(os.Stdout).SetTimeFormatter(gologs.TimeUnix)
@@ -171,13 +418,23 @@ func (log *Logger) Error() *Event {
 //	    }
 //	}
 func (log *Logger) NewWriter(level Level) *Writer {
+	if level > Error {
+		level = Error
+	}
+
 	log.mutex.RLock()
 
 	w := &Writer{
 		event: Event{
-			scratch:       make([]byte, 1, 2048),
-			timeFormatter: log.event.timeFormatter,
-			output:        log.event.output,
+			scratch:           newScratch(log.event.encoder),
+			timeFormatter:     log.event.timeFormatter,
+			durationFormatter: log.event.durationFormatter,
+			output:            log.event.output,
+			encoder:           log.event.encoder,
+			hooks:             log.event.hooks,
+			caller:            log.event.caller,
+			keys:              log.event.keys,
+			counters:          &log.counters,
 		},
 		emitLevel: level,
 		level:     atomic.LoadUint32((*uint32)(&log.level)),
@@ -186,7 +443,8 @@ func (log *Logger) NewWriter(level Level) *Writer {
 		w.branch = make([]byte, len(log.branch))
 		copy(w.branch, log.branch)
 	}
-	w.event.scratch[0] = '{'
+	w.event.prefix = len(w.event.scratch)
+	w.event.caller.skip++ // skip Writer.Write
 
 	log.mutex.RUnlock()
 	return w
@@ -201,9 +459,16 @@ func (log *Logger) With() *Intermediate {
 	log.mutex.RLock()
 
 	il := &Intermediate{
-		timeFormatter: log.event.timeFormatter,
-		output:        log.event.output,
-		level:         atomic.LoadUint32((*uint32)(&log.level)),
+		timeFormatter:     log.event.timeFormatter,
+		durationFormatter: log.event.durationFormatter,
+		output:            log.event.output,
+		encoder:           log.event.encoder,
+		hooks:             log.event.hooks,
+		caller:            log.event.caller,
+		keys:              log.event.keys,
+		sampler:           log.samplerOf(),
+		counting:          log.counters.isEnabled(),
+		level:             atomic.LoadUint32((*uint32)(&log.level)),
 	}
 	if cap(log.branch) > 0 {
 		if len(log.branch) > 0 {
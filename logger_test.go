@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"testing"
+	"time"
 )
 
 // panicyWriter is a test structure used for this test that optionally panics.
@@ -316,6 +317,96 @@ func TestLogger(t *testing.T) {
 				},
 			},
 
+			// time and duration
+			{
+				"time formatted with layout",
+				"{\"level\":\"warning\",\"when\":\"2009-11-10T23:00:00Z\",\"message\":\"time\"}\n",
+				func(l *Logger) {
+					l.Warning().Time("when", time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC), time.RFC3339).Msg("time")
+				},
+			},
+			{
+				"duration as nanoseconds by default",
+				"{\"level\":\"warning\",\"took\":1500000,\"message\":\"duration\"}\n",
+				func(l *Logger) {
+					l.Warning().Duration("took", 1500*time.Microsecond).Msg("duration")
+				},
+			},
+			{
+				"duration as seconds",
+				"{\"level\":\"warning\",\"took\":0.0015,\"message\":\"duration\"}\n",
+				func(l *Logger) {
+					l.SetDurationFormatter(DurationSeconds)
+					l.Warning().Duration("took", 1500*time.Microsecond).Msg("duration")
+				},
+			},
+			{
+				"duration as string",
+				"{\"level\":\"warning\",\"took\":\"1.5ms\",\"timeout\":\"1h2m3.5s\",\"message\":\"duration\"}\n",
+				func(l *Logger) {
+					l.SetDurationFormatter(DurationString)
+					l.Warning().Duration("took", 1500*time.Microsecond).Duration("timeout", time.Hour+2*time.Minute+3500*time.Millisecond).Msg("duration")
+				},
+			},
+			{
+				"branch with time and duration",
+				"{\"level\":\"warning\",\"started\":\"23:00:00\",\"budget\":\"2s\",\"message\":\"branch\"}\n",
+				func(l *Logger) {
+					l.SetDurationFormatter(DurationString).With().
+						Time("started", time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC), "15:04:05").
+						Duration("budget", 2*time.Second).
+						Logger().
+						Warning().Msg("branch")
+				},
+			},
+
+			// nested objects and arrays
+			{
+				"nested object and array",
+				"{\"level\":\"warning\",\"http\":{\"method\":\"GET\",\"status\":200,\"headers\":{\"accept\":\"*/*\"},\"hops\":[\"a\",\"b\"]},\"ids\":[1,2,{\"three\":3},[true,false]],\"message\":\"nested\"}\n",
+				func(l *Logger) {
+					l.Warning().
+						Object("http", func(o *Object) {
+							o.String("method", "GET").
+								Int("status", 200).
+								Object("headers", func(o *Object) { o.String("accept", "*/*") }).
+								Array("hops", func(a *Array) { a.String("a").String("b") })
+						}).
+						Array("ids", func(a *Array) {
+							a.Int(1).Uint(2).
+								Object(func(o *Object) { o.Int("three", 3) }).
+								Array(func(a *Array) { a.Bool(true).Bool(false) })
+						}).
+						Msg("nested")
+				},
+			},
+			{
+				"empty nested object and array",
+				"{\"level\":\"warning\",\"o\":{},\"a\":[]}\n",
+				func(l *Logger) {
+					l.Warning().Object("o", func(*Object) {}).Array("a", func(*Array) {}).Msg("")
+				},
+			},
+			{
+				"nested callbacks not invoked when event not logged",
+				"",
+				func(l *Logger) {
+					l.Debug().Object("o", func(*Object) { panic("should not be invoked") }).Msg("")
+				},
+			},
+			{
+				"branch with nested object and array",
+				"{\"level\":\"warning\",\"service\":{\"name\":\"api\",\"ports\":[80,443]},\"message\":\"branch\"}\n",
+				func(l *Logger) {
+					l.With().
+						Object("service", func(o *Object) {
+							o.String("name", "api").Array("ports", func(a *Array) { a.Int(80).Int(443) })
+						}).
+						Logger().
+						Warning().Msg("branch")
+				},
+			},
+
 			// branches and filtering
 			{
 				"different branches have different levels",
@@ -350,6 +441,25 @@ func TestLogger(t *testing.T) {
 				},
 			},
 
+			// trace level
+			{
+				"trace ignored at debug level",
+				"",
+				func(l *Logger) {
+					l.SetDebug()
+					l.Trace().String("string", "hello").Msg("")
+				},
+			},
+			{
+				"trace logged at trace level",
+				"{\"level\":\"trace\",\"string\":\"hello\"}\n{\"level\":\"debug\",\"string\":\"world\"}\n",
+				func(l *Logger) {
+					l.SetTrace()
+					l.Trace().String("string", "hello").Msg("")
+					l.Debug().String("string", "world").Msg("")
+				},
+			},
+
 			// tracer
 			{
 				"all events logged when tracer is true",
@@ -410,6 +520,19 @@ func BenchmarkLogger(b *testing.B) {
 			}
 		})
 
+		b.Run("with nested object", func(b *testing.B) {
+			want := []byte("{\"level\":\"warning\",\"http\":{\"method\":\"GET\",\"status\":200},\"ids\":[1,2],\"message\":\"with nested object\"}\n")
+
+			for i := 0; i < b.N; i++ {
+				l.Warning().
+					Object("http", func(o *Object) { o.String("method", "GET").Int("status", 200) }).
+					Array("ids", func(a *Array) { a.Int(1).Int(2) }).
+					Msg("with nested object")
+				ensureBytes(b, bb.Bytes(), want)
+				bb.Reset()
+			}
+		})
+
 		b.Run("with string formatting", func(b *testing.B) {
 			want := []byte("{\"level\":\"warning\",\"happy\":true,\"sad\":false,\"usage\":42.3,\"name\":\"First Last\",\"age\":42,\"eye-color\":\"brown\",\"months\":123,\"days\":1234,\"message\":\"with string formatting\"}\n")
 
@@ -430,3 +553,23 @@ func BenchmarkLogger(b *testing.B) {
 		})
 	})
 }
+
+func TestLoggerEnabled(t *testing.T) {
+	log := New(new(bytes.Buffer)).SetInfo()
+
+	for _, level := range []Level{Trace, Debug, Verbose, Info, Warning, Error} {
+		if got, want := log.Enabled(level), level >= Info; got != want {
+			t.Errorf("Level: %v; GOT: %v; WANT: %v", level, got, want)
+		}
+	}
+
+	log.SetLevel(Level(1000))
+	if got, want := log.Enabled(Error), true; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+
+	log = log.With().Tracing(true).Logger()
+	if got, want := log.Enabled(Debug), true; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+}
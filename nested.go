@@ -0,0 +1,272 @@
+package gologs
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Object is used to encode the properties of a nested JSON object. Callers
+// never need to create an Object specifically, but rather receive one as the
+// argument to the callback function provided to the Object method of an
+// Event, an Intermediate, an Object, or an Array.
+//
+//	log.Info().
+//	    Object("http", func(o *gologs.Object) {
+//	        o.String("method", r.Method).Int("status", status)
+//	    }).
+//	    Msg("request")
+//	// Output:
+//	// {"level":"info","http":{"method":"GET","status":200},"message":"request"}
+//
+// An Object is only valid for the duration of the callback function to which
+// it is provided, and must not be retained after that function returns.
+type Object struct {
+	buf               []byte // buf is borrowed from the owning Event or Intermediate
+	durationFormatter DurationFormatter
+}
+
+// Array is used to encode the elements of a nested JSON array. Callers never
+// need to create an Array specifically, but rather receive one as the
+// argument to the callback function provided to the Array method of an
+// Event, an Intermediate, an Object, or an Array.
+//
+//	log.Info().
+//	    Array("ids", func(a *gologs.Array) {
+//	        for _, id := range ids {
+//	            a.Int(id)
+//	        }
+//	    }).
+//	    Msg("batch")
+//	// Output:
+//	// {"level":"info","ids":[1,2,3],"message":"batch"}
+//
+// An Array is only valid for the duration of the callback function to which
+// it is provided, and must not be retained after that function returns.
+//
+// NOTE: Object and Array share the same underlying structure so that a single
+// instance embedded in each Event and Intermediate can be used for every
+// level of nesting without allocating.
+type Array struct {
+	buf               []byte // buf is borrowed from the owning Event or Intermediate
+	durationFormatter DurationFormatter
+}
+
+// appendObject appends the name property with a nested JSON object value to
+// buf, using o to invoke callback while the object is open. Because o borrows
+// buf while callback runs, o may be the same Object whose buffer is being
+// appended to.
+func appendObject(buf []byte, name string, o *Object, callback func(*Object)) []byte {
+	buf = appendEncodedJSONFromString(buf, name)
+	o.buf = append(buf, ':', '{')
+	callback(o)
+	buf = appendCloseNested(o.buf, '}')
+	o.buf = nil
+	return buf
+}
+
+// appendArray appends the name property with a nested JSON array value to
+// buf, using o to invoke callback while the array is open.
+func appendArray(buf []byte, name string, o *Object, callback func(*Array)) []byte {
+	buf = appendEncodedJSONFromString(buf, name)
+	o.buf = append(buf, ':', '[')
+	callback((*Array)(o))
+	buf = appendCloseNested(o.buf, ']')
+	o.buf = nil
+	return buf
+}
+
+// appendCloseNested closes the most recently opened nested object or array,
+// replacing the trailing comma of its final element when it has one.
+func appendCloseNested(buf []byte, closing byte) []byte {
+	if buf[len(buf)-1] == ',' {
+		buf[len(buf)-1] = closing
+	} else {
+		buf = append(buf, closing)
+	}
+	return append(buf, ',')
+}
+
+// Array encodes a nested JSON array property value to the Object using the
+// specified name, invoking callback to add its elements.
+func (o *Object) Array(name string, callback func(*Array)) *Object {
+	o.buf = appendArray(o.buf, name, o, callback)
+	return o
+}
+
+// Bool encodes a boolean property value to the Object using the specified
+// name.
+func (o *Object) Bool(name string, value bool) *Object {
+	o.buf = appendBool(o.buf, name, value)
+	return o
+}
+
+// Duration encodes a time.Duration property value to the Object using the
+// specified name.
+func (o *Object) Duration(name string, value time.Duration) *Object {
+	o.buf = appendDuration(o.buf, name, value, o.durationFormatter)
+	return o
+}
+
+// Float encodes a float64 property value to the Object using the specified
+// name.
+func (o *Object) Float(name string, value float64) *Object {
+	o.buf = appendFloat(o.buf, name, value)
+	return o
+}
+
+// Format encodes a string property value--formatting it with the provided
+// arguments--to the Object using the specified name. This function will
+// invoke fmt.Sprintf() function to format the formatting string with the
+// provided arguments, allocating memory to do so.
+func (o *Object) Format(name, f string, args ...interface{}) *Object {
+	o.buf = appendFormat(o.buf, name, f, args...)
+	return o
+}
+
+// Int encodes a int property value to the Object using the specified name.
+func (o *Object) Int(name string, value int) *Object {
+	o.buf = appendInt(o.buf, name, int64(value))
+	return o
+}
+
+// Int64 encodes a int64 property value to the Object using the specified
+// name.
+func (o *Object) Int64(name string, value int64) *Object {
+	o.buf = appendInt(o.buf, name, value)
+	return o
+}
+
+// Object encodes a nested JSON object property value to the Object using the
+// specified name, invoking callback to add its properties.
+func (o *Object) Object(name string, callback func(*Object)) *Object {
+	o.buf = appendObject(o.buf, name, o, callback)
+	return o
+}
+
+// String encodes a string property value to the Object using the specified
+// name.
+func (o *Object) String(name, value string) *Object {
+	o.buf = appendString(o.buf, name, value)
+	return o
+}
+
+// Stringer encodes the return value of a Stringer to the Object as a property
+// value using the specified name.
+func (o *Object) Stringer(name string, stringer interface{ String() string }) *Object {
+	o.buf = appendString(o.buf, name, stringer.String())
+	return o
+}
+
+// Time encodes a time.Time property value to the Object using the specified
+// name, formatting it with the specified layout.
+func (o *Object) Time(name string, value time.Time, layout string) *Object {
+	o.buf = appendTime(o.buf, name, value, layout)
+	return o
+}
+
+// Uint encodes a uint property value to the Object using the specified name.
+func (o *Object) Uint(name string, value uint) *Object {
+	o.buf = appendUint(o.buf, name, uint64(value))
+	return o
+}
+
+// Uint64 encodes a uint64 property value to the Object using the specified
+// name.
+func (o *Object) Uint64(name string, value uint64) *Object {
+	o.buf = appendUint(o.buf, name, value)
+	return o
+}
+
+// Array encodes a nested JSON array element to the Array, invoking callback
+// to add its elements.
+func (a *Array) Array(callback func(*Array)) *Array {
+	a.buf = append(a.buf, '[')
+	callback(a)
+	a.buf = appendCloseNested(a.buf, ']')
+	return a
+}
+
+// Bool encodes a boolean element to the Array.
+func (a *Array) Bool(value bool) *Array {
+	if value {
+		a.buf = append(a.buf, []byte("true,")...)
+	} else {
+		a.buf = append(a.buf, []byte("false,")...)
+	}
+	return a
+}
+
+// Duration encodes a time.Duration element to the Array.
+func (a *Array) Duration(value time.Duration) *Array {
+	a.buf = append(a.durationFormatter(a.buf, value), ',')
+	return a
+}
+
+// Float encodes a float64 element to the Array.
+func (a *Array) Float(value float64) *Array {
+	a.buf = append(appendEncodedJSONFromFloat(a.buf, value), ',')
+	return a
+}
+
+// Format encodes a string element--formatting it with the provided
+// arguments--to the Array. This function will invoke fmt.Sprintf() function
+// to format the formatting string with the provided arguments, allocating
+// memory to do so.
+func (a *Array) Format(f string, args ...interface{}) *Array {
+	a.buf = append(appendEncodedJSONFromString(a.buf, fmt.Sprintf(f, args...)), ',')
+	return a
+}
+
+// Int encodes a int element to the Array.
+func (a *Array) Int(value int) *Array {
+	a.buf = append(strconv.AppendInt(a.buf, int64(value), 10), ',')
+	return a
+}
+
+// Int64 encodes a int64 element to the Array.
+func (a *Array) Int64(value int64) *Array {
+	a.buf = append(strconv.AppendInt(a.buf, value, 10), ',')
+	return a
+}
+
+// Object encodes a nested JSON object element to the Array, invoking callback
+// to add its properties.
+func (a *Array) Object(callback func(*Object)) *Array {
+	a.buf = append(a.buf, '{')
+	callback((*Object)(a))
+	a.buf = appendCloseNested(a.buf, '}')
+	return a
+}
+
+// String encodes a string element to the Array.
+func (a *Array) String(value string) *Array {
+	a.buf = append(appendEncodedJSONFromString(a.buf, value), ',')
+	return a
+}
+
+// Stringer encodes the return value of a Stringer as an element to the
+// Array.
+func (a *Array) Stringer(stringer interface{ String() string }) *Array {
+	a.buf = append(appendEncodedJSONFromString(a.buf, stringer.String()), ',')
+	return a
+}
+
+// Time encodes a time.Time element to the Array, formatting it with the
+// specified layout.
+func (a *Array) Time(value time.Time, layout string) *Array {
+	a.buf = append(appendEncodedJSONFromTime(a.buf, value, layout), ',')
+	return a
+}
+
+// Uint encodes a uint element to the Array.
+func (a *Array) Uint(value uint) *Array {
+	a.buf = append(strconv.AppendUint(a.buf, uint64(value), 10), ',')
+	return a
+}
+
+// Uint64 encodes a uint64 element to the Array.
+func (a *Array) Uint64(value uint64) *Array {
+	a.buf = append(strconv.AppendUint(a.buf, value, 10), ',')
+	return a
+}
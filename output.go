@@ -5,28 +5,84 @@ import (
 	"sync"
 )
 
+// LevelWriter is an io.Writer that also accepts the level of each event. When
+// the io.Writer of a Logger is a LevelWriter, each event is written using its
+// WriteLevel method rather than its Write method, allowing it to treat events
+// differently based on their levels. Events created by Logger.Log, which have
+// no level, are written with a level of zero.
+type LevelWriter interface {
+	io.Writer
+	WriteLevel(level Level, buf []byte) (int, error)
+}
+
 // output merely ensures only a single Write is invoked at once.
 type output struct {
 	w     io.Writer
+	lw    LevelWriter // lw is w when w is a LevelWriter, otherwise nil
 	mutex sync.Mutex
 }
 
+// newOutput returns a new output that writes to w.
+func newOutput(w io.Writer) *output {
+	o := &output{w: w}
+	o.lw, _ = w.(LevelWriter)
+	return o
+}
+
 // SetWriter directs all future writes to the specified io.Writer, potentially
 // blocking until any in progress event is being written.
 func (o *output) SetWriter(w io.Writer) {
 	o.mutex.Lock()
 	o.w = w
+	o.lw, _ = w.(LevelWriter)
 	o.mutex.Unlock()
 }
 
 // Write writes buf to the underlying io.Writer, potentially blocking until
 // any in progress event is being written.
 func (o *output) Write(buf []byte) (int, error) {
+	return o.WriteLevel(0, buf)
+}
+
+// WriteLevel writes buf, which holds an event at the specified level, to the
+// underlying io.Writer, potentially blocking until any in progress event is
+// being written.
+func (o *output) WriteLevel(level Level, buf []byte) (int, error) {
 	o.mutex.Lock()
 
 	// Using defer here to prevent holding lock if underlying io.Writer
 	// panics.
 	defer o.mutex.Unlock()
 
+	if o.lw != nil {
+		return o.lw.WriteLevel(level, buf)
+	}
 	return o.w.Write(buf)
 }
+
+// Flush flushes the underlying io.Writer when it has either a Flush or a Sync
+// method, potentially blocking until any in progress event is being written.
+func (o *output) Flush() error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	switch w := o.w.(type) {
+	case interface{ Flush() error }:
+		return w.Flush()
+	case interface{ Sync() error }:
+		return w.Sync()
+	}
+	return nil
+}
+
+// Reopen reopens the underlying io.Writer when it has a Reopen method,
+// potentially blocking until any in progress event is being written.
+func (o *output) Reopen() error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if w, ok := o.w.(interface{ Reopen() error }); ok {
+		return w.Reopen()
+	}
+	return nil
+}
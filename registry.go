@@ -0,0 +1,424 @@
+package gologs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Registry is a collection of named Logger branches, whose levels may be
+// viewed and changed while a program runs. A Registry is also an
+// http.Handler, allowing the levels of a live service to be changed without
+// restarting it.
+//
+// Names are hierarchical, with components separated by periods, as in
+// "server.http.handler". A branch whose name extends the name of another
+// branch, such as "server.http" and "server", is a descendant of that branch,
+// and inherits the level of its nearest registered ancestor: it takes that
+// level when it is registered, and follows every later change to it, until
+// its own level is explicitly set. Setting the level of a branch, either
+// using the Registry or using any of the level control methods of its
+// Logger, cascades to all of its descendants that inherit their level.
+//
+//	registry := gologs.NewRegistry()
+//	_ = registry.Register("server", serverLog)
+//	_ = registry.Register("server.http", serverLog.With().String("module", "http").Logger())
+//	_ = registry.Register("database", databaseLog)
+//	http.Handle("/debug/log", registry)
+//
+//	serverLog.SetDebug() // server.http also logs Debug events
+type Registry struct {
+	mutex    sync.Mutex
+	branches map[string]*registeredBranch
+}
+
+// registeredBranch is a Logger branch added to a Registry, along with the
+// state required to inherit its level and to revert a temporary level
+// change.
+type registeredBranch struct {
+	registry      *Registry
+	name          string
+	log           *Logger
+	explicit      bool        // explicit is true after the level is set
+	timer         *time.Timer // timer is not nil while a level change is temporary
+	revert        Level       // revert is the level restored when timer fires
+	revertInherit bool        // revertInherit is true when timer restores inheritance
+	expires       time.Time   // expires is when timer fires
+}
+
+// BranchStatus describes the state of a Logger branch in a Registry.
+type BranchStatus struct {
+	Name      string `json:"name"`
+	Level     Level  `json:"level"`
+	Tracing   bool   `json:"tracing"`
+	Inherited bool   `json:"inherited,omitempty"`
+
+	// Dropped is the number of events the branch did not log because its
+	// Sampler did not allow them.
+	Dropped uint64 `json:"dropped,omitempty"`
+
+	// Revert is the level the branch will revert to at Expires, when its
+	// current level was set temporarily.
+	Revert *Level `json:"revert,omitempty"`
+
+	// Expires is when the branch will revert to the Revert level, formatted
+	// using time.RFC3339, or empty when its current level is not temporary.
+	Expires string `json:"expires,omitempty"`
+}
+
+// NewRegistry returns a new Registry without any branches.
+func NewRegistry() *Registry {
+	return &Registry{branches: make(map[string]*registeredBranch)}
+}
+
+// Register adds log to the Registry using the specified name. When a branch
+// is registered using an ancestor of name, log takes the level of the
+// nearest of them, and inherits its level from then on. Branches registered
+// before their ancestors begin inheriting their level when the first of
+// their ancestors is registered. It returns an error
+// when name is empty or has an empty component, when log is nil or already
+// in a Registry, or when another Logger is already registered using name.
+func (r *Registry) Register(name string, log *Logger) error {
+	if name == "" {
+		return fmt.Errorf("cannot register branch: empty name")
+	}
+	for _, component := range strings.Split(name, ".") {
+		if component == "" {
+			return fmt.Errorf("cannot register branch %q: empty name component", name)
+		}
+	}
+	if log == nil {
+		return fmt.Errorf("cannot register branch %q: nil Logger", name)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.branches[name]; ok {
+		return fmt.Errorf("cannot register branch %q: name already registered", name)
+	}
+
+	b := &registeredBranch{registry: r, name: name, log: log}
+
+	log.mutex.Lock()
+	if log.node != nil {
+		log.mutex.Unlock()
+		return fmt.Errorf("cannot register branch %q: Logger already registered as %q", name, log.node.name)
+	}
+	log.node = b
+	log.mutex.Unlock()
+
+	if parent := r.parent(name); parent != nil {
+		log.storeLevel(parent.log.Level())
+	}
+	r.branches[name] = b
+	r.cascade(name)
+	return nil
+}
+
+// Unregister removes the Logger registered using name from the Registry,
+// cancelling any pending revert of its level. Descendants that inherited
+// their level from it inherit from its nearest registered ancestor instead,
+// or keep their current level until one is registered.
+func (r *Registry) Unregister(name string) {
+	r.mutex.Lock()
+	if b, ok := r.branches[name]; ok {
+		if b.timer != nil {
+			// Clear the timer so it is ignored should it fire before it
+			// was stopped.
+			b.timer.Stop()
+			b.timer = nil
+		}
+		b.log.mutex.Lock()
+		b.log.node = nil
+		b.log.mutex.Unlock()
+		delete(r.branches, name)
+		r.cascade(name)
+	}
+	r.mutex.Unlock()
+}
+
+// Lookup returns the Logger registered using name, or nil when no Logger is
+// registered using name.
+func (r *Registry) Lookup(name string) *Logger {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if b, ok := r.branches[name]; ok {
+		return b.log
+	}
+	return nil
+}
+
+// Status returns the status of every branch in the Registry, sorted by name.
+func (r *Registry) Status() []BranchStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	statuses := make([]BranchStatus, 0, len(r.branches))
+	for name, b := range r.branches {
+		statuses = append(statuses, b.status(name))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// SetLevel changes the level of the Logger registered using name, explicitly
+// overriding any level it inherited, and cascading the change to its
+// descendants that inherit their level. When ttl is greater than zero, the
+// change is temporary, and after ttl elapses the branch reverts to the level
+// it had, or to inheriting its level, before the first of any consecutive
+// temporary changes. When ttl is not greater than zero, the change is
+// permanent, and any pending revert is cancelled. It returns an error when no
+// Logger is registered using name.
+//
+//	// Log Debug events from the server branch for the next ten minutes.
+//	err := registry.SetLevel("server", gologs.Debug, 10*time.Minute)
+func (r *Registry) SetLevel(name string, level Level, ttl time.Duration) error {
+	_, err := r.setLevel(name, level, ttl)
+	return err
+}
+
+// Inherit causes the Logger registered using name to inherit the level of its
+// nearest registered ancestor, cancelling any pending revert of its level,
+// and cascading the change to its descendants that inherit their level. It
+// returns an error when no Logger is registered using name, or when no
+// ancestor of name is registered.
+func (r *Registry) Inherit(name string) error {
+	_, err := r.inherit(name)
+	return err
+}
+
+// setLevel changes the level of the Logger registered using name, and returns
+// its resulting status.
+func (r *Registry) setLevel(name string, level Level, ttl time.Duration) (BranchStatus, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	b, ok := r.branches[name]
+	if !ok {
+		return BranchStatus{}, fmt.Errorf("cannot set level of branch %q: name not registered", name)
+	}
+	r.setBranchLevel(b, level, ttl)
+	return b.status(name), nil
+}
+
+// setLevelOf changes the level of the Logger of the registered branch,
+// returning false without changing it when the branch is no longer
+// registered.
+func (r *Registry) setLevelOf(b *registeredBranch, level Level) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.branches[b.name] != b {
+		return false
+	}
+	r.setBranchLevel(b, level, 0)
+	return true
+}
+
+// setBranchLevel changes the level of the Logger of the registered branch. It
+// must be invoked while the Registry is locked.
+func (r *Registry) setBranchLevel(b *registeredBranch, level Level, ttl time.Duration) {
+	if b.timer != nil {
+		// Consecutive temporary changes revert to the state in effect before
+		// the first of them.
+		b.timer.Stop()
+		b.timer = nil
+	} else if ttl > 0 {
+		b.revert = b.log.Level()
+		b.revertInherit = !b.explicit && r.parent(b.name) != nil
+	}
+	b.explicit = true
+	b.log.storeLevel(level)
+
+	if ttl > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(ttl, func() {
+			r.mutex.Lock()
+			// Ignore a timer that was stopped too late to prevent it from
+			// firing.
+			if b.timer == timer {
+				b.timer = nil
+				if parent := r.parent(b.name); b.revertInherit && parent != nil {
+					b.explicit = false
+					b.log.storeLevel(parent.log.Level())
+				} else {
+					b.log.storeLevel(b.revert)
+				}
+				r.cascade(b.name)
+			}
+			r.mutex.Unlock()
+		})
+		b.timer = timer
+		b.expires = time.Now().Add(ttl)
+	}
+
+	r.cascade(b.name)
+}
+
+// inherit causes the Logger registered using name to inherit its level, and
+// returns its resulting status.
+func (r *Registry) inherit(name string) (BranchStatus, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	b, ok := r.branches[name]
+	if !ok {
+		return BranchStatus{}, fmt.Errorf("cannot inherit level of branch %q: name not registered", name)
+	}
+	parent := r.parent(name)
+	if parent == nil {
+		return BranchStatus{}, fmt.Errorf("cannot inherit level of branch %q: no ancestor registered", name)
+	}
+
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.explicit = false
+	b.log.storeLevel(parent.log.Level())
+
+	r.cascade(name)
+	return b.status(name), nil
+}
+
+// parent returns the nearest registered ancestor of name, or nil when no
+// ancestor of name is registered. It must be invoked while the Registry is
+// locked.
+func (r *Registry) parent(name string) *registeredBranch {
+	for i := strings.LastIndexByte(name, '.'); i > 0; i = strings.LastIndexByte(name[:i], '.') {
+		if b, ok := r.branches[name[:i]]; ok {
+			return b
+		}
+	}
+	return nil
+}
+
+// cascade updates the level of every descendant of name that inherits its
+// level. It must be invoked while the Registry is locked.
+func (r *Registry) cascade(name string) {
+	prefix := name + "."
+
+	var descendants []*registeredBranch
+	for n, b := range r.branches {
+		if strings.HasPrefix(n, prefix) && !b.explicit {
+			descendants = append(descendants, b)
+		}
+	}
+	// Because every ancestor of a name sorts before it, sorting by name
+	// updates every branch before any of its descendants.
+	sort.Slice(descendants, func(i, j int) bool { return descendants[i].name < descendants[j].name })
+
+	for _, b := range descendants {
+		if parent := r.parent(b.name); parent != nil {
+			b.log.storeLevel(parent.log.Level())
+		}
+	}
+}
+
+// status returns the status of the branch, which must be invoked while the
+// Registry is locked.
+func (b *registeredBranch) status(name string) BranchStatus {
+	status := BranchStatus{
+		Name:      name,
+		Level:     b.log.Level(),
+		Tracing:   b.log.Tracing(),
+		Inherited: !b.explicit && b.registry.parent(name) != nil,
+		Dropped:   b.log.Dropped(),
+	}
+	if b.timer != nil {
+		revert := b.revert
+		status.Revert = &revert
+		status.Expires = b.expires.Format(time.RFC3339)
+	}
+	return status
+}
+
+// ServeHTTP responds to GET requests with the JSON encoded status of every
+// branch in the Registry, or of only the branch specified by the name
+// parameter when it is provided. It responds to PUT and POST requests by
+// changing the level of the branch specified by the name parameter to the
+// level parameter, parsed by ParseLevel, and responding with the JSON encoded
+// status of that branch. When a PUT or POST request includes the ttl
+// parameter, parsed by time.ParseDuration, the change is temporary. When the
+// level parameter is "inherit", the branch inherits its level from its
+// nearest registered ancestor instead. Parameters may be provided in the URL
+// query or in a form encoded request body.
+//
+//	curl http://localhost:8080/debug/log
+//	curl -X PUT 'http://localhost:8080/debug/log?name=server&level=debug&ttl=10m'
+//	curl -X PUT 'http://localhost:8080/debug/log?name=server.http&level=inherit'
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		name := req.FormValue("name")
+		if name == "" {
+			writeJSON(w, http.StatusOK, r.Status())
+			return
+		}
+		r.mutex.Lock()
+		b, ok := r.branches[name]
+		var status BranchStatus
+		if ok {
+			status = b.status(name)
+		}
+		r.mutex.Unlock()
+		if !ok {
+			http.Error(w, fmt.Sprintf("cannot find branch %q", name), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+
+	case http.MethodPut, http.MethodPost:
+		name := req.FormValue("name")
+		if name == "" {
+			http.Error(w, "cannot set level: missing name parameter", http.StatusBadRequest)
+			return
+		}
+		if req.FormValue("level") == "inherit" {
+			status, err := r.inherit(name)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			writeJSON(w, http.StatusOK, status)
+			return
+		}
+		level, err := ParseLevel(req.FormValue("level"))
+		if err != nil {
+			http.Error(w, "cannot set level: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		var ttl time.Duration
+		if s := req.FormValue("ttl"); s != "" {
+			ttl, err = time.ParseDuration(s)
+			if err != nil || ttl <= 0 {
+				http.Error(w, fmt.Sprintf("cannot set level: invalid ttl: %q", s), http.StatusBadRequest)
+				return
+			}
+		}
+		status, err := r.setLevel(name, level, ttl)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT")
+		http.Error(w, fmt.Sprintf("cannot %s: method not allowed", req.Method), http.StatusMethodNotAllowed)
+	}
+}
+
+// writeJSON writes the JSON encoding of v as the response with the specified
+// status code.
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,280 @@
+package gologs
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRegistry(tb testing.TB) *Registry {
+	tb.Helper()
+	root := New(new(bytes.Buffer)).SetInfo()
+	r := NewRegistry()
+	if err := r.Register("server", root.With().String("module", "server").Logger()); err != nil {
+		tb.Fatal(err)
+	}
+	if err := r.Register("database", root.With().Tracing(true).Logger().SetWarning()); err != nil {
+		tb.Fatal(err)
+	}
+	if err := r.Register("server.http", root.With().Logger().SetError()); err != nil {
+		tb.Fatal(err)
+	}
+	return r
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("register", func(t *testing.T) {
+		r := newTestRegistry(t)
+		ensureError(t, r.Register("", New(nil)), "empty name")
+		ensureError(t, r.Register("cache", nil), "nil Logger")
+		ensureError(t, r.Register("server", New(nil)), "name already registered")
+
+		if r.Lookup("server") == nil {
+			t.Errorf("GOT: nil; WANT: Logger")
+		}
+		r.Unregister("server")
+		if got := r.Lookup("server"); got != nil {
+			t.Errorf("GOT: %v; WANT: nil", got)
+		}
+	})
+
+	t.Run("set level", func(t *testing.T) {
+		r := newTestRegistry(t)
+		ensureError(t, r.SetLevel("server", Debug, 0))
+		if got, want := r.Lookup("server").Level(), Debug; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		ensureError(t, r.SetLevel("cache", Debug, 0), "name not registered")
+	})
+
+	t.Run("set level temporarily", func(t *testing.T) {
+		r := newTestRegistry(t)
+		ensureError(t, r.SetLevel("server", Verbose, time.Hour))
+		ensureError(t, r.SetLevel("server", Debug, 10*time.Millisecond))
+
+		statuses := r.Status()
+		if got, want := statuses[1].Level, Debug; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := statuses[1].Revert, Info; got == nil || *got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		deadline := time.Now().Add(5 * time.Second)
+		for r.Lookup("server").Level() != Info {
+			if time.Now().After(deadline) {
+				t.Fatalf("GOT: %v; WANT: %v", r.Lookup("server").Level(), Info)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if got := r.Status()[1].Revert; got != nil {
+			t.Errorf("GOT: %v; WANT: nil", *got)
+		}
+	})
+
+	t.Run("permanent change cancels revert", func(t *testing.T) {
+		r := newTestRegistry(t)
+		ensureError(t, r.SetLevel("server", Debug, 10*time.Millisecond))
+		ensureError(t, r.SetLevel("server", Error, 0))
+		time.Sleep(50 * time.Millisecond)
+		if got, want := r.Lookup("server").Level(), Error; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("unregister cancels revert", func(t *testing.T) {
+		r := newTestRegistry(t)
+		log := r.Lookup("server")
+		ensureError(t, r.SetLevel("server", Debug, 10*time.Millisecond))
+		r.Unregister("server")
+		time.Sleep(50 * time.Millisecond)
+		if got, want := log.Level(), Debug; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+}
+
+func TestRegistryHierarchy(t *testing.T) {
+	root := New(new(bytes.Buffer)).SetInfo()
+	server := root.With().Logger()
+	http := server.With().Logger()
+	handler := http.With().Logger()
+	database := root.With().Logger().SetError()
+
+	ensureLevels := func(t *testing.T, want ...Level) {
+		t.Helper()
+		for i, log := range []*Logger{server, http, handler, database} {
+			if got := log.Level(); got != want[i] {
+				t.Errorf("Logger: %d; GOT: %v; WANT: %v", i, got, want[i])
+			}
+		}
+	}
+
+	r := NewRegistry()
+	ensureError(t, r.Register("server.http.handler", handler))
+	ensureError(t, r.Register("server", server.SetWarning()))
+	ensureError(t, r.Register("server.http", http))
+	ensureError(t, r.Register("server-database", database))
+	ensureError(t, r.Register("server..http", root), "empty name component")
+	ensureError(t, r.Register("root", handler), `Logger already registered as "server.http.handler"`)
+
+	// Registering server made server.http.handler inherit from it, although
+	// it was registered first, and server-database is not a descendant of
+	// server.
+	ensureLevels(t, Warning, Warning, Warning, Error)
+
+	t.Run("cascade", func(t *testing.T) {
+		server.SetDebug()
+		ensureLevels(t, Debug, Debug, Debug, Error)
+	})
+
+	t.Run("override", func(t *testing.T) {
+		http.SetVerbose()
+		server.SetTrace()
+		ensureLevels(t, Trace, Verbose, Verbose, Error)
+	})
+
+	t.Run("inherit", func(t *testing.T) {
+		ensureError(t, r.Inherit("server.http"))
+		ensureLevels(t, Trace, Trace, Trace, Error)
+		ensureError(t, r.Inherit("server"), "no ancestor registered")
+	})
+
+	t.Run("temporary override", func(t *testing.T) {
+		ensureError(t, r.SetLevel("server.http", Warning, 10*time.Millisecond))
+		ensureLevels(t, Trace, Warning, Warning, Error)
+
+		deadline := time.Now().Add(5 * time.Second)
+		for handler.Level() != Trace {
+			if time.Now().After(deadline) {
+				t.Fatalf("GOT: %v; WANT: %v", handler.Level(), Trace)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		server.SetInfo()
+		ensureLevels(t, Info, Info, Info, Error)
+	})
+
+	t.Run("unregister", func(t *testing.T) {
+		r.Unregister("server.http")
+		server.SetWarning()
+		ensureLevels(t, Warning, Info, Warning, Error)
+
+		// An unregistered Logger no longer cascades.
+		http.SetDebug()
+		ensureLevels(t, Warning, Debug, Warning, Error)
+	})
+}
+
+func TestRegistryServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		code   int
+		want   string
+	}{
+		{
+			"list branches",
+			http.MethodGet, "/", "",
+			http.StatusOK,
+			`[{"name":"database","level":"warning","tracing":true},{"name":"server","level":"info","tracing":false},{"name":"server.http","level":"info","tracing":false,"inherited":true}]`,
+		},
+		{
+			"show branch",
+			http.MethodGet, "/?name=server", "",
+			http.StatusOK,
+			`{"name":"server","level":"info","tracing":false}`,
+		},
+		{
+			"show unknown branch",
+			http.MethodGet, "/?name=cache", "",
+			http.StatusNotFound,
+			`cannot find branch "cache"`,
+		},
+		{
+			"put level in query",
+			http.MethodPut, "/?name=server&level=DEBUG", "",
+			http.StatusOK,
+			`{"name":"server","level":"debug","tracing":false}`,
+		},
+		{
+			"post level in form",
+			http.MethodPost, "/", "name=database&level=err",
+			http.StatusOK,
+			`{"name":"database","level":"error","tracing":true}`,
+		},
+		{
+			"put level with ttl",
+			http.MethodPut, "/?name=server&level=trace&ttl=1h", "",
+			http.StatusOK,
+			`{"name":"server","level":"trace","tracing":false,"revert":"info","expires":`,
+		},
+		{
+			"put inherit",
+			http.MethodPut, "/?name=server.http&level=inherit", "",
+			http.StatusOK,
+			`{"name":"server.http","level":"info","tracing":false,"inherited":true}`,
+		},
+		{
+			"put inherit without ancestor",
+			http.MethodPut, "/?name=server&level=inherit", "",
+			http.StatusNotFound,
+			"no ancestor registered",
+		},
+		{
+			"missing name",
+			http.MethodPut, "/?level=debug", "",
+			http.StatusBadRequest,
+			"missing name parameter",
+		},
+		{
+			"invalid level",
+			http.MethodPut, "/?name=server&level=loud", "",
+			http.StatusBadRequest,
+			`cannot parse level: "loud"`,
+		},
+		{
+			"invalid ttl",
+			http.MethodPut, "/?name=server&level=debug&ttl=-1s", "",
+			http.StatusBadRequest,
+			`invalid ttl: "-1s"`,
+		},
+		{
+			"put unknown branch",
+			http.MethodPut, "/?name=cache&level=debug", "",
+			http.StatusNotFound,
+			"name not registered",
+		},
+		{
+			"method not allowed",
+			http.MethodDelete, "/?name=server", "",
+			http.StatusMethodNotAllowed,
+			"method not allowed",
+		},
+	}
+
+	for _, single := range tests {
+		t.Run(single.name, func(t *testing.T) {
+			r := newTestRegistry(t)
+
+			req := httptest.NewRequest(single.method, single.target, strings.NewReader(single.body))
+			if single.body != "" {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if got, want := rec.Code, single.code; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+			if got, want := rec.Body.String(), single.want; !strings.Contains(got, want) {
+				t.Errorf("\nGOT:  %q\nWANT: %q\n", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package gologs
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sampler decides whether a Logger logs an event, which allows a Logger to
+// log only some of a flood of similar events. The decision is made before the
+// event is formatted, so a dropped event costs no more than an event below
+// the level of the Logger.
+//
+// A Sampler is consulted for every leveled event that the level of its Logger
+// allows, except events at the Fatal and Panic levels, which are always
+// logged. Events created by Logger.Log, and events of Loggers with tracing
+// enabled, are not sampled. A Sampler must be safe for concurrent use.
+type Sampler interface {
+	// Sample returns true when an event at the specified level should be
+	// logged.
+	Sample(level Level) bool
+}
+
+// sampler wraps a Sampler so it can be stored in an atomic.Value, which
+// requires every stored value to have the same concrete type.
+type sampler struct {
+	Sampler
+}
+
+// IntervalSampler is a Sampler that allows the first events in each interval,
+// and then only one of every so many events for the remainder of that
+// interval. Intervals are consecutive multiples of the interval duration
+// since the Unix epoch.
+type IntervalSampler struct {
+	// state holds the number of the current interval in its upper 32 bits,
+	// and the number of events in the current interval in its lower 32
+	// bits, so both are changed together by a single atomic operation.
+	state      uint64
+	interval   int64
+	first      uint64
+	thereafter uint64
+	now        func() time.Time
+}
+
+// NewIntervalSampler returns a new IntervalSampler that allows the first
+// events of each interval, then one of every thereafter events for the
+// remainder of that interval. When thereafter is zero, no events are allowed
+// after the first events. It panics when interval is not greater than zero.
+//
+//	// Log the first 10 events each second, then every 100th event.
+//	log = log.With().Sampler(gologs.NewIntervalSampler(time.Second, 10, 100)).Logger()
+func NewIntervalSampler(interval time.Duration, first, thereafter uint64) *IntervalSampler {
+	if interval <= 0 {
+		panic("cannot create IntervalSampler without positive interval")
+	}
+	return &IntervalSampler{
+		interval:   int64(interval),
+		first:      first,
+		thereafter: thereafter,
+		now:        time.Now,
+	}
+}
+
+// maxIntervalCount is the greatest number of events an IntervalSampler counts
+// in an interval. Later events in the same interval are counted as this one.
+const maxIntervalCount = 1<<32 - 1
+
+// Sample returns true when the event is one of the first events of the
+// current interval, or is one of every thereafter events after those. It
+// does not block.
+func (s *IntervalSampler) Sample(_ Level) bool {
+	current := uint64(uint32(s.now().UnixNano() / s.interval))
+	var n uint64
+	for {
+		state := atomic.LoadUint64(&s.state)
+		n = state&maxIntervalCount + 1
+		if state>>32 != current {
+			n = 1 // first event of a new interval
+		} else if n > maxIntervalCount {
+			n = maxIntervalCount
+		}
+		if atomic.CompareAndSwapUint64(&s.state, state, current<<32|n) {
+			break
+		}
+	}
+	if n <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
+
+// TokenBucketSampler is a Sampler that limits the rate of events using a
+// token bucket, which allows bursts of events up to the size of the bucket,
+// while limiting the sustained rate of events.
+type TokenBucketSampler struct {
+	rate   float64 // rate is the number of tokens added per second
+	burst  float64 // burst is the capacity of the bucket
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+	mutex  sync.Mutex
+}
+
+// NewTokenBucketSampler returns a new TokenBucketSampler that allows rate
+// events per second on average, and bursts of up to burst events. Its bucket
+// starts full. It panics when either rate or burst is not greater than zero.
+//
+//	// Log at most 5 events per second, with bursts of up to 20 events.
+//	log.SetSampler(gologs.NewTokenBucketSampler(5, 20))
+func NewTokenBucketSampler(rate float64, burst int) *TokenBucketSampler {
+	if rate <= 0 || burst <= 0 {
+		panic("cannot create TokenBucketSampler without positive rate and burst")
+	}
+	s := &TokenBucketSampler{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+	s.last = s.now()
+	return s
+}
+
+// Sample returns true and removes a token from the bucket when the bucket
+// has a token, after refilling the bucket for the time elapsed since the
+// previous invocation.
+func (s *TokenBucketSampler) Sample(_ Level) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	if elapsed := now.Sub(s.last); elapsed > 0 {
+		s.tokens += elapsed.Seconds() * s.rate
+		if s.tokens > s.burst {
+			s.tokens = s.burst
+		}
+	}
+	s.last = now
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+// RandomSampler is a Sampler that allows events at random.
+type RandomSampler struct {
+	n uint32
+}
+
+// NewRandomSampler returns a new RandomSampler that allows each event with a
+// probability of one in n. When n is less than two, every event is allowed.
+//
+//	// Log one of every 10 events at random.
+//	log.SetSampler(gologs.NewRandomSampler(10))
+func NewRandomSampler(n uint32) *RandomSampler {
+	return &RandomSampler{n: n}
+}
+
+// Sample returns true with a probability of one in n.
+func (s *RandomSampler) Sample(_ Level) bool {
+	return s.n < 2 || rand.Int63n(int64(s.n)) == 0
+}
@@ -0,0 +1,191 @@
+package gologs
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock is a test structure that returns a time advanced by the test.
+type fakeClock struct {
+	t time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Date(2022, 8, 6, 15, 14, 4, 0, time.UTC)}
+}
+
+func (fc *fakeClock) now() time.Time { return fc.t }
+
+func (fc *fakeClock) advance(d time.Duration) { fc.t = fc.t.Add(d) }
+
+// ensureSamples invokes Sample once for each character of want, and ensures
+// the returned values match want, which is a string of 1 and 0 characters.
+func ensureSamples(tb testing.TB, s Sampler, want string) {
+	tb.Helper()
+	got := make([]byte, len(want))
+	for i := range got {
+		got[i] = '0'
+		if s.Sample(Info) {
+			got[i] = '1'
+		}
+	}
+	ensureBytes(tb, got, []byte(want))
+}
+
+func TestIntervalSampler(t *testing.T) {
+	t.Run("thereafter", func(t *testing.T) {
+		fc := newFakeClock()
+		s := NewIntervalSampler(time.Second, 3, 4)
+		s.now = fc.now
+
+		ensureSamples(t, s, "11100010001")
+		fc.advance(500 * time.Millisecond)
+		ensureSamples(t, s, "00010")
+		fc.advance(500 * time.Millisecond)
+		ensureSamples(t, s, "1110")
+	})
+
+	t.Run("none thereafter", func(t *testing.T) {
+		fc := newFakeClock()
+		s := NewIntervalSampler(time.Second, 2, 0)
+		s.now = fc.now
+
+		ensureSamples(t, s, "110000")
+		fc.advance(time.Second)
+		ensureSamples(t, s, "1100")
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		fc := newFakeClock()
+		s := NewIntervalSampler(time.Second, 10, 100)
+		s.now = fc.now
+
+		var allowed uint64
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 1000; j++ {
+					if s.Sample(Info) {
+						atomic.AddUint64(&allowed, 1)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		// The first 10 of 8000 events, then every 100th of the remaining
+		// 7990 events.
+		if got, want := allowed, uint64(10+79); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("invalid interval", func(t *testing.T) {
+		ensurePanic(t, "cannot create IntervalSampler without positive interval", func() {
+			NewIntervalSampler(0, 1, 1)
+		})
+	})
+}
+
+func TestTokenBucketSampler(t *testing.T) {
+	fc := newFakeClock()
+	s := NewTokenBucketSampler(2, 3)
+	s.now = fc.now
+	s.last = fc.now()
+
+	ensureSamples(t, s, "1110")
+	fc.advance(500 * time.Millisecond)
+	ensureSamples(t, s, "10")
+	fc.advance(time.Hour) // refills no more than the burst
+	ensureSamples(t, s, "1110")
+
+	ensurePanic(t, "cannot create TokenBucketSampler without positive rate and burst", func() {
+		NewTokenBucketSampler(1, 0)
+	})
+}
+
+func TestRandomSampler(t *testing.T) {
+	ensureSamples(t, NewRandomSampler(1), "1111")
+
+	var allowed int
+	s := NewRandomSampler(4)
+	for i := 0; i < 10000; i++ {
+		if s.Sample(Info) {
+			allowed++
+		}
+	}
+	if allowed < 2000 || allowed > 3000 {
+		t.Errorf("GOT: %v; WANT: about 2500", allowed)
+	}
+}
+
+func TestLoggerSampler(t *testing.T) {
+	t.Run("drops before formatting", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		log := New(bb).SetInfo().SetSampler(NewIntervalSampler(time.Hour, 1, 0))
+
+		var formatted int
+		property := func(*Object) { formatted++ }
+		log.Warning().Object("o", property).Msg("1")
+		log.Warning().Object("o", property).Msg("2")
+		log.Error().Object("o", property).Msg("3")
+		log.Debug().Object("o", property).Msg("below level")
+		log.WithLevel(Info).Object("o", property).Msg("4")
+		log.Log().Msg("5")
+
+		ensureBytes(t, bb.Bytes(), []byte("{\"level\":\"warning\",\"o\":{},\"message\":\"1\"}\n{\"message\":\"5\"}\n"))
+		if got, want := formatted, 1; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		if got, want := log.Dropped(), uint64(3); got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+
+		// Removing the Sampler logs every event.
+		bb.Reset()
+		log.SetSampler(nil).Warning().Msg("6")
+		ensureBytes(t, bb.Bytes(), []byte("{\"level\":\"warning\",\"message\":\"6\"}\n"))
+	})
+
+	t.Run("branches", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		parent := New(bb).SetInfo().SetSampler(NewIntervalSampler(time.Hour, 1, 0))
+		shared := parent.With().String("branch", "shared").Logger()
+		own := parent.With().String("branch", "own").Sampler(NewIntervalSampler(time.Hour, 2, 0)).Logger()
+		tracer := parent.With().String("branch", "tracer").Tracing(true).Logger()
+
+		for i := 0; i < 3; i++ {
+			parent.Info().Msg("parent")
+			shared.Info().Msg("shared")
+			own.Info().Msg("own")
+			tracer.Info().Msg("tracer")
+		}
+
+		ensureBytes(t, bb.Bytes(), []byte(""+
+			"{\"level\":\"info\",\"message\":\"parent\"}\n"+
+			"{\"level\":\"info\",\"branch\":\"own\",\"message\":\"own\"}\n"+
+			"{\"level\":\"info\",\"branch\":\"tracer\",\"message\":\"tracer\"}\n"+
+			"{\"level\":\"info\",\"branch\":\"own\",\"message\":\"own\"}\n"+
+			"{\"level\":\"info\",\"branch\":\"tracer\",\"message\":\"tracer\"}\n"+
+			"{\"level\":\"info\",\"branch\":\"tracer\",\"message\":\"tracer\"}\n"))
+
+		for i, single := range []struct {
+			log  *Logger
+			want uint64
+		}{
+			{parent, 2},
+			{shared, 3},
+			{own, 1},
+			{tracer, 0},
+		} {
+			if got := single.log.Dropped(); got != single.want {
+				t.Errorf("Logger: %d; GOT: %v; WANT: %v", i, got, single.want)
+			}
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package gologs
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// SignalHandler changes the levels of one or more Loggers, and reopens their
+// underlying io.Writers, when the program receives signals. It is created by
+// HandleSignals, and its signal handlers are removed by its Stop method.
+type SignalHandler struct {
+	loggers []*Logger
+	signals chan os.Signal
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+}
+
+// HandleSignals installs signal handlers that control the specified Loggers,
+// which is useful for long running services that do not provide another way
+// to control their log levels. On platforms that support them, SIGUSR1 lowers
+// the level of each Logger to the next more verbose predefined level, for
+// instance from Info to Verbose, SIGUSR2 raises the level of each Logger to
+// the next less verbose predefined level, for instance from Info to Warning,
+// and SIGHUP reopens the underlying io.Writer of each Logger using its Reopen
+// method. Each level change is logged by the affected Logger, regardless of
+// its level. On platforms without these signals, no handlers are installed.
+//
+// Loggers in a Registry cascade each level change to their descendants, so
+// passing the root of a tree of branches controls the entire tree.
+//
+//	sh := gologs.HandleSignals(log)
+//	defer sh.Stop()
+func HandleSignals(loggers ...*Logger) *SignalHandler {
+	sh := &SignalHandler{
+		loggers: loggers,
+		signals: make(chan os.Signal, 1),
+		done:    make(chan struct{}),
+	}
+	if len(handledSignals) > 0 {
+		// Notify without any signals would relay every signal.
+		signal.Notify(sh.signals, handledSignals...)
+	}
+	sh.wg.Add(1)
+	go sh.run()
+	return sh
+}
+
+// Stop removes the signal handlers installed by HandleSignals, and waits for
+// any signal being handled to complete. It may be invoked more than once.
+func (sh *SignalHandler) Stop() {
+	sh.once.Do(func() {
+		signal.Stop(sh.signals)
+		close(sh.done)
+		sh.wg.Wait()
+	})
+}
+
+func (sh *SignalHandler) run() {
+	defer sh.wg.Done()
+	for {
+		select {
+		case <-sh.done:
+			return
+		case sig := <-sh.signals:
+			sh.handle(sig)
+		}
+	}
+}
+
+// handle performs the action associated with sig for each Logger.
+func (sh *SignalHandler) handle(sig os.Signal) {
+	switch sig {
+	case signalMoreVerbose:
+		sh.step(sig, true)
+	case signalLessVerbose:
+		sh.step(sig, false)
+	case signalReopen:
+		sh.reopen(sig)
+	}
+}
+
+// step changes the level of each Logger to the adjacent predefined level,
+// either more or less verbose than its current level, and logs the change.
+func (sh *SignalHandler) step(sig os.Signal, moreVerbose bool) {
+	for _, log := range sh.loggers {
+		previous := log.Level()
+		level := stepLevel(previous, moreVerbose)
+		if level == previous {
+			continue
+		}
+		log.SetLevel(level)
+		log.Log().
+			String("signal", sig.String()).
+			Stringer("from", previous).
+			Stringer("to", level).
+			Msg("log level changed")
+	}
+}
+
+// reopen reopens the underlying io.Writer of each Logger, only once for
+// Loggers that share an io.Writer, and logs any error.
+func (sh *SignalHandler) reopen(sig os.Signal) {
+	reopened := make(map[*output]struct{}, len(sh.loggers))
+	for _, log := range sh.loggers {
+		if _, ok := reopened[log.event.output]; ok {
+			continue
+		}
+		reopened[log.event.output] = struct{}{}
+		if err := log.Reopen(); err != nil {
+			log.Error().String("signal", sig.String()).Err(err).Msg("cannot reopen output")
+		}
+	}
+}
+
+// stepLevel returns the predefined level from Trace through Error adjacent to
+// level, either more or less verbose than it. Levels at or beyond either end
+// of that range are not changed in that direction, and custom levels step to
+// the nearest predefined level in the requested direction.
+func stepLevel(level Level, moreVerbose bool) Level {
+	if moreVerbose {
+		for l := Error; l >= Trace; l -= 10 {
+			if l < level {
+				return l
+			}
+		}
+	} else {
+		for l := Trace; l <= Error; l += 10 {
+			if l > level {
+				return l
+			}
+		}
+	}
+	return level
+}
@@ -0,0 +1,14 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+
+package gologs
+
+import "os"
+
+// This platform does not have the signals used to control Loggers, so
+// HandleSignals installs no handlers.
+var (
+	signalMoreVerbose os.Signal
+	signalLessVerbose os.Signal
+	signalReopen      os.Signal
+	handledSignals    []os.Signal
+)
@@ -0,0 +1,28 @@
+package gologs
+
+import "testing"
+
+func TestStepLevel(t *testing.T) {
+	tests := []struct {
+		level       Level
+		moreVerbose bool
+		want        Level
+	}{
+		{Info, true, Verbose},
+		{Debug, true, Trace},
+		{Trace, true, Trace},
+		{Fatal, true, Error},
+		{testNotice, true, Info},
+		{Info, false, Warning},
+		{Warning, false, Error},
+		{Error, false, Error},
+		{Panic, false, Panic},
+		{testNotice, false, Warning},
+	}
+
+	for _, single := range tests {
+		if got, want := stepLevel(single.level, single.moreVerbose), single.want; got != want {
+			t.Errorf("Level: %v; More Verbose: %v; GOT: %v; WANT: %v", single.level, single.moreVerbose, got, want)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package gologs
+
+import (
+	"os"
+	"syscall"
+)
+
+var (
+	signalMoreVerbose os.Signal = syscall.SIGUSR1
+	signalLessVerbose os.Signal = syscall.SIGUSR2
+	signalReopen      os.Signal = syscall.SIGHUP
+	handledSignals              = []os.Signal{signalMoreVerbose, signalLessVerbose, signalReopen}
+)
@@ -0,0 +1,88 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package gologs
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// reopenBuffer is a test structure that records how many times it was
+// reopened, and may be read while it is written by another goroutine.
+type reopenBuffer struct {
+	buf     bytes.Buffer
+	reopens int
+	err     error
+	mutex   sync.Mutex
+}
+
+func (rb *reopenBuffer) Write(p []byte) (int, error) {
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	return rb.buf.Write(p)
+}
+
+func (rb *reopenBuffer) Reopen() error {
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	rb.reopens++
+	return rb.err
+}
+
+// waitFor sends sig to the test process, then waits until the buffer
+// contains want.
+func (rb *reopenBuffer) waitFor(tb testing.TB, sig syscall.Signal, want string) {
+	tb.Helper()
+	if err := syscall.Kill(os.Getpid(), sig); err != nil {
+		tb.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rb.mutex.Lock()
+		got := rb.buf.String()
+		rb.mutex.Unlock()
+		if strings.Contains(got, want) {
+			return
+		}
+		if time.Now().After(deadline) {
+			tb.Fatalf("\nGOT:  %q\nWANT: %q\n", got, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHandleSignals(t *testing.T) {
+	rb := &reopenBuffer{err: errors.New("reopen-boom!")}
+	log := New(rb).SetInfo()
+	child := log.With().String("module", "child").Logger().SetWarning()
+
+	sh := HandleSignals(log, child)
+	defer sh.Stop()
+
+	rb.waitFor(t, syscall.SIGUSR1, "{\"module\":\"child\",\"signal\":\"user defined signal 1\",\"from\":\"WARNING\",\"to\":\"INFO\",\"message\":\"log level changed\"}\n")
+	if got, want := log.Level(), Verbose; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+
+	rb.waitFor(t, syscall.SIGUSR2, "{\"module\":\"child\",\"signal\":\"user defined signal 2\",\"from\":\"INFO\",\"to\":\"WARNING\",\"message\":\"log level changed\"}\n")
+	if got, want := log.Level(), Info; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+
+	// Both Loggers share an io.Writer, which is only reopened once.
+	rb.waitFor(t, syscall.SIGHUP, "{\"level\":\"error\",\"signal\":\"hangup\",\"error\":\"reopen-boom!\",\"message\":\"cannot reopen output\"}\n")
+	sh.Stop()
+	sh.Stop()
+
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	if got, want := rb.reopens, 1; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+}
@@ -0,0 +1,281 @@
+//go:build go1.21
+
+// Package sloghandler provides a log/slog Handler backed by a *gologs.Logger.
+//
+// It allows code written against the standard library's structured logging
+// API to share a single gologs tree, with its per-branch levels and tracing,
+// with code written against gologs directly.
+//
+//	log := gologs.New(os.Stderr).SetInfo()
+//	slog.SetDefault(slog.New(sloghandler.New(log)))
+//	slog.Info("started", "pid", os.Getpid())
+//	// Output:
+//	// {"level":"info","pid":1234,"message":"started"}
+//
+// Levels are mapped from slog to gologs as follows: levels below
+// slog.LevelDebug become gologs.Trace, slog.LevelDebug becomes gologs.Debug,
+// levels between slog.LevelDebug and slog.LevelInfo become gologs.Verbose,
+// levels from slog.LevelInfo up to slog.LevelWarn become gologs.Info, levels
+// from slog.LevelWarn up to slog.LevelError become gologs.Warning, and
+// slog.LevelError and above become gologs.Error.
+//
+// Attributes added using WithAttrs become properties of a new gologs branch,
+// so they are encoded once rather than for every event. Groups added using
+// WithGroup become nested objects. Because the properties of a branch are
+// complete before any event is logged, attributes added after a group has
+// been opened are kept by the Handler and encoded for every event instead.
+//
+// The time of each slog.Record is ignored; the time of each event is
+// determined by the TimeFormatter of the gologs Logger.
+package sloghandler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/karrick/gologs"
+)
+
+// Handler is a slog.Handler that logs each record to a *gologs.Logger.
+type Handler struct {
+	log    *gologs.Logger
+	groups []string       // groups are the names of the open groups
+	attrs  []groupedAttrs // attrs are the attributes added after the first group was opened
+}
+
+// groupedAttrs are attributes added using WithAttrs after depth groups were
+// opened.
+type groupedAttrs struct {
+	depth int
+	attrs []slog.Attr
+}
+
+// New returns a new Handler that logs each record to log.
+func New(log *gologs.Logger) *Handler {
+	return &Handler{log: log}
+}
+
+// Level returns the gologs Level that corresponds to the slog Level.
+func Level(level slog.Level) gologs.Level {
+	switch {
+	case level < slog.LevelDebug:
+		return gologs.Trace
+	case level == slog.LevelDebug:
+		return gologs.Debug
+	case level < slog.LevelInfo:
+		return gologs.Verbose
+	case level < slog.LevelWarn:
+		return gologs.Info
+	case level < slog.LevelError:
+		return gologs.Warning
+	default:
+		return gologs.Error
+	}
+}
+
+// Enabled returns true when the Logger would log an event at the gologs
+// Level that corresponds to level.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.log.Enabled(Level(level))
+}
+
+// Handle logs the record as an event of the Logger.
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	event := h.log.WithLevel(Level(r.Level))
+	if event == nil {
+		return nil
+	}
+
+	h.appendGroup(eventFields{event}, 0, r)
+	return event.Msg(r.Message)
+}
+
+// WithAttrs returns a new Handler whose events include attrs. When no group
+// is open, the attributes become properties of a new gologs branch.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	if len(h.groups) == 0 {
+		il := h.log.With()
+		for _, a := range attrs {
+			appendAttr(intermediateFields{il}, a)
+		}
+		return &Handler{log: il.Logger()}
+	}
+	h2 := &Handler{
+		log:    h.log,
+		groups: h.groups,
+		attrs:  make([]groupedAttrs, len(h.attrs), len(h.attrs)+1),
+	}
+	copy(h2.attrs, h.attrs)
+	h2.attrs = append(h2.attrs, groupedAttrs{depth: len(h.groups), attrs: attrs})
+	return h2
+}
+
+// WithGroup returns a new Handler whose attributes, including those of every
+// record it handles, are nested in an object with the specified name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := &Handler{
+		log:    h.log,
+		groups: make([]string, len(h.groups), len(h.groups)+1),
+		attrs:  h.attrs,
+	}
+	copy(h2.groups, h.groups)
+	h2.groups = append(h2.groups, name)
+	return h2
+}
+
+// appendGroup appends the attributes added after depth groups were opened,
+// then either the next group or the attributes of the record to f.
+func (h *Handler) appendGroup(f fields, depth int, r slog.Record) {
+	for _, ga := range h.attrs {
+		if ga.depth == depth {
+			for _, a := range ga.attrs {
+				appendAttr(f, a)
+			}
+		}
+	}
+	if depth == len(h.groups) {
+		r.Attrs(func(a slog.Attr) bool {
+			appendAttr(f, a)
+			return true
+		})
+		return
+	}
+	if !h.hasAttrs(depth+1, r) {
+		return // slog omits empty groups
+	}
+	f.Object(h.groups[depth], func(o *gologs.Object) {
+		h.appendGroup(objectFields{o}, depth+1, r)
+	})
+}
+
+// hasAttrs returns true when any attributes would be appended to the group
+// at the specified depth or any group nested within it.
+func (h *Handler) hasAttrs(depth int, r slog.Record) bool {
+	if r.NumAttrs() > 0 {
+		return true
+	}
+	for _, ga := range h.attrs {
+		if ga.depth >= depth && len(ga.attrs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// appendAttr appends the attribute to f, following the rules of slog
+// handlers: values are resolved, empty attributes are ignored, and the
+// attributes of groups with empty names are inlined.
+func appendAttr(f fields, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindBool:
+		f.Bool(a.Key, a.Value.Bool())
+	case slog.KindDuration:
+		f.Duration(a.Key, a.Value.Duration())
+	case slog.KindFloat64:
+		f.Float(a.Key, a.Value.Float64())
+	case slog.KindInt64:
+		f.Int64(a.Key, a.Value.Int64())
+	case slog.KindString:
+		f.String(a.Key, a.Value.String())
+	case slog.KindTime:
+		f.Time(a.Key, a.Value.Time(), time.RFC3339Nano)
+	case slog.KindUint64:
+		f.Uint64(a.Key, a.Value.Uint64())
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return
+		}
+		if a.Key == "" {
+			for _, ga := range attrs {
+				appendAttr(f, ga)
+			}
+			return
+		}
+		f.Object(a.Key, func(o *gologs.Object) {
+			for _, ga := range attrs {
+				appendAttr(objectFields{o}, ga)
+			}
+		})
+	default:
+		switch v := a.Value.Any().(type) {
+		case error:
+			f.String(a.Key, v.Error())
+		case fmt.Stringer:
+			f.String(a.Key, v.String())
+		default:
+			f.String(a.Key, fmt.Sprintf("%+v", v))
+		}
+	}
+}
+
+// fields is the set of methods common to gologs Event, Intermediate, and
+// Object, used to append attributes to any of them.
+type fields interface {
+	Bool(name string, value bool)
+	Duration(name string, value time.Duration)
+	Float(name string, value float64)
+	Int64(name string, value int64)
+	Object(name string, callback func(*gologs.Object))
+	String(name, value string)
+	Time(name string, value time.Time, layout string)
+	Uint64(name string, value uint64)
+}
+
+type eventFields struct{ e *gologs.Event }
+
+func (f eventFields) Bool(name string, value bool)              { f.e.Bool(name, value) }
+func (f eventFields) Duration(name string, value time.Duration) { f.e.Duration(name, value) }
+func (f eventFields) Float(name string, value float64)          { f.e.Float(name, value) }
+func (f eventFields) Int64(name string, value int64)            { f.e.Int64(name, value) }
+func (f eventFields) Object(name string, callback func(*gologs.Object)) {
+	f.e.Object(name, callback)
+}
+func (f eventFields) String(name, value string) { f.e.String(name, value) }
+func (f eventFields) Time(name string, value time.Time, layout string) {
+	f.e.Time(name, value, layout)
+}
+func (f eventFields) Uint64(name string, value uint64) { f.e.Uint64(name, value) }
+
+type intermediateFields struct{ il *gologs.Intermediate }
+
+func (f intermediateFields) Bool(name string, value bool)              { f.il.Bool(name, value) }
+func (f intermediateFields) Duration(name string, value time.Duration) { f.il.Duration(name, value) }
+func (f intermediateFields) Float(name string, value float64)          { f.il.Float(name, value) }
+func (f intermediateFields) Int64(name string, value int64)            { f.il.Int64(name, value) }
+func (f intermediateFields) Object(name string, callback func(*gologs.Object)) {
+	f.il.Object(name, callback)
+}
+func (f intermediateFields) String(name, value string) { f.il.String(name, value) }
+func (f intermediateFields) Time(name string, value time.Time, layout string) {
+	f.il.Time(name, value, layout)
+}
+func (f intermediateFields) Uint64(name string, value uint64) { f.il.Uint64(name, value) }
+
+type objectFields struct{ o *gologs.Object }
+
+func (f objectFields) Bool(name string, value bool)              { f.o.Bool(name, value) }
+func (f objectFields) Duration(name string, value time.Duration) { f.o.Duration(name, value) }
+func (f objectFields) Float(name string, value float64)          { f.o.Float(name, value) }
+func (f objectFields) Int64(name string, value int64)            { f.o.Int64(name, value) }
+func (f objectFields) Object(name string, callback func(*gologs.Object)) {
+	f.o.Object(name, callback)
+}
+func (f objectFields) String(name, value string) { f.o.String(name, value) }
+func (f objectFields) Time(name string, value time.Time, layout string) {
+	f.o.Time(name, value, layout)
+}
+func (f objectFields) Uint64(name string, value uint64) { f.o.Uint64(name, value) }
@@ -0,0 +1,103 @@
+//go:build go1.21
+
+package sloghandler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/karrick/gologs"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		call func(*slog.Logger)
+	}{
+		{
+			"levels are mapped and gated",
+			"{\"level\":\"info\",\"message\":\"info\"}\n{\"level\":\"warning\",\"message\":\"warn\"}\n{\"level\":\"error\",\"message\":\"error\"}\n",
+			func(l *slog.Logger) {
+				l.Debug("debug")
+				l.Log(context.Background(), slog.LevelDebug+1, "verbose")
+				l.Info("info")
+				l.Warn("warn")
+				l.Error("error")
+			},
+		},
+		{
+			"attribute kinds",
+			"{\"level\":\"info\",\"b\":true,\"d\":1500000,\"f\":3.14,\"i\":-42,\"s\":\"str\",\"t\":\"2009-11-10T23:00:00Z\",\"u\":42,\"err\":\"boom\",\"any\":\"[1 2]\",\"message\":\"kinds\"}\n",
+			func(l *slog.Logger) {
+				l.Info("kinds",
+					slog.Bool("b", true),
+					slog.Duration("d", 1500*time.Microsecond),
+					slog.Float64("f", 3.14),
+					slog.Int("i", -42),
+					slog.String("s", "str"),
+					slog.Time("t", time.Date(2009, 11, 10, 23, 0, 0, 0, time.UTC)),
+					slog.Uint64("u", 42),
+					slog.Any("err", errors.New("boom")),
+					slog.Any("any", []int{1, 2}),
+				)
+			},
+		},
+		{
+			"empty attributes are ignored and empty groups inlined",
+			"{\"level\":\"info\",\"a\":1,\"b\":2,\"message\":\"inline\"}\n",
+			func(l *slog.Logger) {
+				l.Info("inline", slog.Attr{}, slog.Int("a", 1), slog.Group("", slog.Int("b", 2)), slog.Group("empty"))
+			},
+		},
+		{
+			"attributes become branch properties",
+			"{\"level\":\"info\",\"module\":\"server\",\"http\":{\"method\":\"GET\"},\"status\":200,\"message\":\"request\"}\n",
+			func(l *slog.Logger) {
+				l.With("module", "server").Info("request", slog.Group("http", slog.String("method", "GET")), "status", 200)
+			},
+		},
+		{
+			"groups become nested objects",
+			"{\"level\":\"info\",\"module\":\"server\",\"request\":{\"id\":7,\"http\":{\"method\":\"GET\",\"status\":200}},\"message\":\"nested\"}\n",
+			func(l *slog.Logger) {
+				l.With("module", "server").
+					WithGroup("request").With("id", 7).
+					WithGroup("http").With("method", "GET").
+					Info("nested", "status", 200)
+			},
+		},
+		{
+			"empty groups are omitted",
+			"{\"level\":\"info\",\"message\":\"empty\"}\n",
+			func(l *slog.Logger) {
+				l.WithGroup("request").Info("empty")
+			},
+		},
+	}
+
+	for _, single := range tests {
+		t.Run(single.name, func(t *testing.T) {
+			bb := new(bytes.Buffer)
+			single.call(slog.New(New(gologs.New(bb).SetInfo())))
+			if got, want := bb.String(), single.want; got != want {
+				t.Errorf("\nGOT:  %q\nWANT: %q\n", got, want)
+			}
+		})
+	}
+
+	t.Run("enabled follows tracing", func(t *testing.T) {
+		log := gologs.New(new(bytes.Buffer)).SetError()
+		if got, want := New(log).Enabled(context.Background(), slog.LevelDebug), false; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+		log = log.With().Tracing(true).Logger()
+		if got, want := New(log).Enabled(context.Background(), slog.LevelDebug), true; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+}
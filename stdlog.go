@@ -0,0 +1,171 @@
+package gologs
+
+import (
+	"bytes"
+	stdlog "log"
+)
+
+// stdLogSkip is the number of stack frames between the code that invoked a
+// method of a standard library *log.Logger, such as Print, and the Msg method
+// of the event: the Print and output methods of the *log.Logger, and the
+// Write method of the StdLogWriter.
+const stdLogSkip = 3
+
+// StdLogWriter is an io.Writer that conveys each line written by a standard
+// library *log.Logger to a Logger as an individual log event. The date and
+// time the standard library adds to each line are removed, because the Logger
+// adds its own time when configured to do so.
+//
+// Each event is logged at the level with which the StdLogWriter was created,
+// subject to the current level of the Logger. When ParseLevels is enabled, a
+// line beginning with a level token such as "[ERROR]", "WARN:", or "debug:"
+// is instead logged at the corresponding level, and the token is removed from
+// the message. Levels above Error, whether the StdLogWriter was created with
+// one or parsed one from a line such as "[FATAL]" or "panic:", are logged at
+// the Error level, so a line never exits the program or panics.
+//
+//	srv := &http.Server{
+//	    ErrorLog: log.NewStdLogWriter(gologs.Warning).Logger(),
+//	}
+type StdLogWriter struct {
+	log         *Logger
+	level       Level
+	parseLevels bool
+}
+
+// NewStdLogWriter returns a StdLogWriter that logs each line it receives to
+// log at the specified level, or at Error when level is above Error.
+func (log *Logger) NewStdLogWriter(level Level) *StdLogWriter {
+	if level > Error {
+		level = Error
+	}
+	return &StdLogWriter{log: log, level: level}
+}
+
+// ParseLevels causes the StdLogWriter to log each line that begins with a
+// level token at the corresponding level, rather than at the level with which
+// it was created.
+func (w *StdLogWriter) ParseLevels(value bool) *StdLogWriter {
+	w.parseLevels = value
+	return w
+}
+
+// Logger returns a new standard library *log.Logger that writes to w, with no
+// prefix and no flags.
+func (w *StdLogWriter) Logger() *stdlog.Logger {
+	return stdlog.New(w, "", 0)
+}
+
+// Redirect changes the output of the standard library's default logger to w,
+// removing its prefix and flags, and returns a function that restores its
+// previous output, prefix, and flags.
+//
+//	restore := log.NewStdLogWriter(gologs.Info).ParseLevels(true).Redirect()
+//	defer restore()
+func (w *StdLogWriter) Redirect() func() {
+	flags, prefix, output := stdlog.Flags(), stdlog.Prefix(), stdlog.Writer()
+	stdlog.SetFlags(0)
+	stdlog.SetPrefix("")
+	stdlog.SetOutput(w)
+	return func() {
+		stdlog.SetOutput(output)
+		stdlog.SetPrefix(prefix)
+		stdlog.SetFlags(flags)
+	}
+}
+
+// Write logs buf as an event, after removing its trailing newline and any
+// date and time at its beginning.
+//
+// It always returns the length of buf, along with any error from writing the
+// event to the Logger's underlying io.Writer.
+func (w *StdLogWriter) Write(buf []byte) (int, error) {
+	n := len(buf)
+	for len(buf) > 0 && (buf[len(buf)-1] == '\n' || buf[len(buf)-1] == '\r') {
+		buf = buf[:len(buf)-1]
+	}
+	buf = trimStdLogTimestamp(buf)
+
+	level := w.level
+	if w.parseLevels {
+		if l, rest, ok := parseLevelToken(buf); ok {
+			level, buf = l, rest
+			if level > Error {
+				// A line from another library must never exit the program
+				// or panic, so Fatal and Panic lines are logged as errors.
+				level = Error
+			}
+		}
+	}
+
+	event := w.log.WithLevel(level)
+	if event != nil {
+		event.skip = stdLogSkip
+	}
+	return n, event.Msg(string(buf))
+}
+
+// trimStdLogTimestamp returns buf without the date, formatted as
+// "2009/01/23 ", and the time, formatted as "01:23:23 " or
+// "01:23:23.123123 ", that the standard library log package adds to the
+// beginning of each line depending on its flags.
+func trimStdLogTimestamp(buf []byte) []byte {
+	if matchDigits(buf, "dddd/dd/dd ") {
+		buf = buf[11:]
+	}
+	if matchDigits(buf, "dd:dd:dd.dddddd ") {
+		buf = buf[16:]
+	} else if matchDigits(buf, "dd:dd:dd ") {
+		buf = buf[9:]
+	}
+	return buf
+}
+
+// matchDigits returns true when buf begins with pattern, where each 'd' in
+// pattern matches any decimal digit, and every other byte matches itself.
+func matchDigits(buf []byte, pattern string) bool {
+	if len(buf) < len(pattern) {
+		return false
+	}
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == 'd' {
+			if buf[i] < '0' || buf[i] > '9' {
+				return false
+			}
+		} else if buf[i] != pattern[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseLevelToken returns the level named by the token at the beginning of
+// buf, and the remainder of buf following the token and any spaces. The token
+// is a case insensitive level name either enclosed in square brackets, as in
+// "[WARN]", or followed by a colon, as in "error:".
+func parseLevelToken(buf []byte) (Level, []byte, bool) {
+	var name, rest []byte
+
+	if len(buf) > 0 && buf[0] == '[' {
+		i := bytes.IndexByte(buf, ']')
+		if i < 0 {
+			return 0, buf, false
+		}
+		name, rest = buf[1:i], buf[i+1:]
+	} else {
+		i := bytes.IndexByte(buf, ':')
+		if i < 0 {
+			return 0, buf, false
+		}
+		name, rest = buf[:i], buf[i+1:]
+	}
+
+	level, ok := levelFromName(name)
+	if !ok {
+		return 0, buf, false
+	}
+	for len(rest) > 0 && rest[0] == ' ' {
+		rest = rest[1:]
+	}
+	return level, rest, true
+}
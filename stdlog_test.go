@@ -0,0 +1,83 @@
+package gologs
+
+import (
+	"bytes"
+	stdlog "log"
+	"testing"
+)
+
+func TestStdLogWriter(t *testing.T) {
+	t.Run("logger", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		log := New(bb).SetInfo()
+
+		sl := log.NewStdLogWriter(Info).Logger()
+		sl.Printf("hello %s", "world")
+		sl.SetFlags(stdlog.LstdFlags | stdlog.Lmicroseconds)
+		sl.Print("with timestamp")
+		sl.Print("[ERROR] not parsed")
+
+		want := "{\"level\":\"info\",\"message\":\"hello world\"}\n{\"level\":\"info\",\"message\":\"with timestamp\"}\n{\"level\":\"info\",\"message\":\"[ERROR] not parsed\"}\n"
+		ensureBytes(t, bb.Bytes(), []byte(want))
+	})
+
+	t.Run("parse levels", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		log := New(bb).SetInfo()
+
+		sl := log.NewStdLogWriter(Info).ParseLevels(true).Logger()
+		sl.Print("[ERROR] bracketed")
+		sl.Print("warn: colon")
+		sl.Print("Debug: below level")
+		sl.Print("key: not a level")
+		sl.Print("[unterminated")
+
+		want := "{\"level\":\"error\",\"message\":\"bracketed\"}\n{\"level\":\"warning\",\"message\":\"colon\"}\n{\"level\":\"info\",\"message\":\"key: not a level\"}\n{\"level\":\"info\",\"message\":\"[unterminated\"}\n"
+		ensureBytes(t, bb.Bytes(), []byte(want))
+	})
+
+	t.Run("terminating levels", func(t *testing.T) {
+		defer func(f func(int)) { exitFunc = f }(exitFunc)
+		var exited bool
+		exitFunc = func(int) { exited = true }
+
+		bb := new(bytes.Buffer)
+		log := New(bb).SetInfo()
+
+		sl := log.NewStdLogWriter(Info).ParseLevels(true).Logger()
+		ensureNoPanic(t, "panic line", func() {
+			sl.Print("[FATAL] cannot continue")
+			sl.Print("panic: unexpected state")
+		})
+		ensureNoPanic(t, "panic writer", func() {
+			log.NewStdLogWriter(Fatal).Logger().Print("cannot start")
+			log.NewStdLogWriter(Panic).Logger().Print("cannot stop")
+		})
+
+		if exited {
+			t.Errorf("GOT: %v; WANT: %v", exited, false)
+		}
+		want := "" +
+			"{\"level\":\"error\",\"message\":\"cannot continue\"}\n" +
+			"{\"level\":\"error\",\"message\":\"unexpected state\"}\n" +
+			"{\"level\":\"error\",\"message\":\"cannot start\"}\n" +
+			"{\"level\":\"error\",\"message\":\"cannot stop\"}\n"
+		ensureBytes(t, bb.Bytes(), []byte(want))
+	})
+
+	t.Run("redirect", func(t *testing.T) {
+		bb := new(bytes.Buffer)
+		log := New(bb)
+
+		restore := log.NewStdLogWriter(Warning).Redirect()
+		stdlog.Print("redirected")
+		restore()
+
+		want := "{\"level\":\"warning\",\"message\":\"redirected\"}\n"
+		ensureBytes(t, bb.Bytes(), []byte(want))
+
+		if got, want := stdlog.Flags(), stdlog.LstdFlags; got != want {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,337 @@
+// Package syslog provides an io.Writer that sends log events to a syslog
+// daemon, either the local daemon over a Unix socket, or a remote collector
+// over UDP or TCP. Each event is framed according to either RFC 5424 or RFC
+// 3164, and its gologs Level is mapped to a syslog severity.
+//
+//	sw, err := syslog.Dial("", "") // local syslog daemon
+//	if err != nil {
+//	    panic(err)
+//	}
+//	defer sw.Close()
+//	sw.SetFacility(syslog.Daemon).SetAppName("service")
+//	log := gologs.New(sw)
+//
+// The gologs Level of each event is mapped to a syslog severity as follows:
+//
+//	Trace, Debug, Verbose: debug
+//	Info:                  informational
+//	Warning:               warning
+//	Error:                 err
+//	Fatal:                 crit
+//	Panic:                 alert
+//	no level (Logger.Log): notice
+//
+// Custom levels are mapped like the nearest predefined level below them.
+package syslog
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/karrick/gologs"
+)
+
+// Format specifies how a Writer frames each event.
+type Format uint8
+
+const (
+	// RFC5424 frames each event as specified by RFC 5424, and is the default
+	// Format.
+	RFC5424 Format = iota
+
+	// RFC3164 frames each event as specified by RFC 3164, which is the
+	// format understood by older syslog daemons.
+	RFC3164
+)
+
+// Facility specifies the type of program sending events to syslog.
+type Facility uint8
+
+// Facility values are defined by RFC 5424.
+const (
+	Kern Facility = iota
+	User
+	Mail
+	Daemon
+	Auth
+	Syslog
+	Lpr
+	News
+	Uucp
+	Cron
+	Authpriv
+	Ftp
+	_ // ntp
+	_ // log audit
+	_ // log alert
+	_ // clock
+	Local0
+	Local1
+	Local2
+	Local3
+	Local4
+	Local5
+	Local6
+	Local7
+)
+
+// Severity values are defined by RFC 5424.
+const (
+	severityAlert         = 1
+	severityCritical      = 2
+	severityError         = 3
+	severityWarning       = 4
+	severityNotice        = 5
+	severityInformational = 6
+	severityDebug         = 7
+)
+
+// localSockets are the pathnames where syslog daemons listen on various
+// platforms.
+var localSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// now returns the current time, and is replaced by tests.
+var now = time.Now
+
+// Writer is a gologs.LevelWriter that sends each event to a syslog daemon. It
+// is safe for concurrent use.
+type Writer struct {
+	network  string
+	address  string
+	format   Format
+	facility Facility
+	hostname string
+	appName  string
+	procID   string
+
+	conn   net.Conn
+	stream bool // stream is true when conn requires each message to be delimited
+	buf    []byte
+	mutex  sync.Mutex
+}
+
+// Dial returns a new Writer that sends events to the syslog daemon at address
+// on the specified network, which may be "udp", "tcp", "unix", "unixgram", or
+// any other network accepted by net.Dial. When network is empty, the Writer
+// sends events to the local syslog daemon, and address is ignored.
+//
+// The new Writer uses the RFC5424 Format, the User Facility, the host name
+// reported by the kernel, and the base name of the program as its app-name.
+func Dial(network, address string) (*Writer, error) {
+	w := &Writer{
+		network:  network,
+		address:  address,
+		facility: User,
+		appName:  filepath.Base(os.Args[0]),
+		procID:   strconv.Itoa(os.Getpid()),
+	}
+	w.hostname, _ = os.Hostname()
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// SetFormat changes how the Writer frames each event.
+func (w *Writer) SetFormat(format Format) *Writer {
+	w.mutex.Lock()
+	w.format = format
+	w.mutex.Unlock()
+	return w
+}
+
+// SetFacility changes the facility of each event.
+func (w *Writer) SetFacility(facility Facility) *Writer {
+	w.mutex.Lock()
+	w.facility = facility
+	w.mutex.Unlock()
+	return w
+}
+
+// SetHostname changes the host name included in each event. An empty host
+// name is sent as the RFC 5424 nil value, "-".
+func (w *Writer) SetHostname(hostname string) *Writer {
+	w.mutex.Lock()
+	w.hostname = hostname
+	w.mutex.Unlock()
+	return w
+}
+
+// SetAppName changes the app-name, or tag in RFC 3164 terms, included in each
+// event. An empty app-name is sent as the RFC 5424 nil value, "-".
+func (w *Writer) SetAppName(appName string) *Writer {
+	w.mutex.Lock()
+	w.appName = appName
+	w.mutex.Unlock()
+	return w
+}
+
+// Write sends buf as an event without a level, which has the notice severity.
+func (w *Writer) Write(buf []byte) (int, error) {
+	return w.WriteLevel(0, buf)
+}
+
+// WriteLevel sends buf, which holds an event at the specified level, to the
+// syslog daemon, framed according to the Format of the Writer. When sending
+// fails, the Writer reconnects to the syslog daemon and sends the event once
+// more.
+func (w *Writer) WriteLevel(level gologs.Level, buf []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	// Events end with a newline, which is not part of the syslog message.
+	msg := buf
+	if l := len(msg); l > 0 && msg[l-1] == '\n' {
+		msg = msg[:l-1]
+	}
+	if w.conn != nil {
+		if _, err := w.conn.Write(w.frame(level, msg)); err == nil {
+			return len(buf), nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	if err := w.connect(); err != nil {
+		return 0, err
+	}
+	if _, err := w.conn.Write(w.frame(level, msg)); err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		return 0, fmt.Errorf("cannot write to syslog: %w", err)
+	}
+	return len(buf), nil
+}
+
+// Close closes the connection to the syslog daemon.
+func (w *Writer) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// connect connects to the syslog daemon. It must be invoked while the Writer
+// is locked or before the Writer is shared.
+func (w *Writer) connect() error {
+	if w.network != "" {
+		conn, err := net.Dial(w.network, w.address)
+		if err != nil {
+			return fmt.Errorf("cannot connect to syslog: %w", err)
+		}
+		w.conn = conn
+		w.stream = isStream(w.network)
+		return nil
+	}
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, pathname := range localSockets {
+			if conn, err := net.Dial(network, pathname); err == nil {
+				w.conn = conn
+				w.stream = isStream(network)
+				return nil
+			}
+		}
+	}
+	return errors.New("cannot connect to syslog: local syslog daemon not found")
+}
+
+// isStream returns true when network is a stream protocol, which requires
+// each message to be delimited.
+func isStream(network string) bool {
+	switch network {
+	case "udp", "udp4", "udp6", "unixgram":
+		return false
+	}
+	return true
+}
+
+// frame returns msg framed as a syslog message at the specified level, using
+// the buffer of the Writer. It must be invoked while the Writer is locked.
+func (w *Writer) frame(level gologs.Level, msg []byte) []byte {
+	t := now()
+
+	// RFC 6587 frames RFC 5424 messages sent over stream protocols by
+	// prefixing each message with its length, and RFC 3164 messages by
+	// appending a newline to each message. Room for the length is reserved
+	// at the start of the buffer.
+	const reserved = 11
+	buf := append(w.buf[:0], "0000000000 "...)
+
+	buf = append(buf, '<')
+	buf = strconv.AppendInt(buf, int64(w.facility)*8+int64(severity(level)), 10)
+	buf = append(buf, '>')
+
+	switch w.format {
+	case RFC3164:
+		buf = t.AppendFormat(buf, time.Stamp)
+		buf = append(buf, ' ')
+		buf = append(buf, nilValue(w.hostname)...)
+		buf = append(buf, ' ')
+		buf = append(buf, nilValue(w.appName)...)
+		buf = append(buf, '[')
+		buf = append(buf, w.procID...)
+		buf = append(buf, "]: "...)
+		buf = append(buf, msg...)
+		if w.stream {
+			buf = append(buf, '\n')
+		}
+	default:
+		buf = append(buf, "1 "...)
+		buf = t.AppendFormat(buf, "2006-01-02T15:04:05.000000Z07:00")
+		buf = append(buf, ' ')
+		buf = append(buf, nilValue(w.hostname)...)
+		buf = append(buf, ' ')
+		buf = append(buf, nilValue(w.appName)...)
+		buf = append(buf, ' ')
+		buf = append(buf, w.procID...)
+		buf = append(buf, " - - "...) // neither MSGID nor STRUCTURED-DATA
+		buf = append(buf, msg...)
+	}
+
+	w.buf = buf
+	if !w.stream || w.format != RFC5424 {
+		return buf[reserved:]
+	}
+	var length [reserved]byte
+	l := strconv.AppendInt(length[:0], int64(len(buf)-reserved), 10)
+	start := reserved - len(l) - 1
+	copy(buf[start:], l)
+	return buf[start:]
+}
+
+// nilValue returns s, or the RFC 5424 nil value when s is empty.
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// severity returns the syslog severity for level.
+func severity(level gologs.Level) int {
+	switch {
+	case level == 0:
+		return severityNotice
+	case level < gologs.Info:
+		return severityDebug
+	case level < gologs.Warning:
+		return severityInformational
+	case level < gologs.Error:
+		return severityWarning
+	case level < gologs.Fatal:
+		return severityError
+	case level < gologs.Panic:
+		return severityCritical
+	}
+	return severityAlert
+}
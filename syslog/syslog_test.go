@@ -0,0 +1,214 @@
+package syslog
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/karrick/gologs"
+)
+
+// setNow replaces the current time used by the package for the duration of
+// the test.
+func setNow(tb testing.TB) {
+	tb.Helper()
+	previous := now
+	now = func() time.Time { return time.Date(2022, 8, 6, 15, 14, 4, 123456789, time.UTC) }
+	tb.Cleanup(func() { now = previous })
+}
+
+// dialTest returns a new Writer connected to address on network, configured
+// with fixed header fields.
+func dialTest(tb testing.TB, network, address string) *Writer {
+	tb.Helper()
+	w, err := Dial(network, address)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { _ = w.Close() })
+	w.procID = "42"
+	return w.SetHostname("host").SetAppName("app")
+}
+
+// readPacket returns the next datagram received by pc.
+func readPacket(tb testing.TB, pc net.PacketConn) string {
+	tb.Helper()
+	if err := pc.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		tb.Fatal(err)
+	}
+	buf := make([]byte, 4096)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return string(buf[:n])
+}
+
+func ensureString(tb testing.TB, got, want string) {
+	tb.Helper()
+	if got != want {
+		tb.Errorf("\nGOT:  %q\nWANT: %q\n", got, want)
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		level gologs.Level
+		want  int
+	}{
+		{0, severityNotice},
+		{gologs.Trace, severityDebug},
+		{gologs.Debug, severityDebug},
+		{gologs.Verbose, severityDebug},
+		{gologs.Info, severityInformational},
+		{gologs.Info + 5, severityInformational},
+		{gologs.Warning, severityWarning},
+		{gologs.Error, severityError},
+		{gologs.Fatal, severityCritical},
+		{gologs.Panic, severityAlert},
+		{gologs.Panic + 5, severityAlert},
+	}
+
+	for _, single := range tests {
+		t.Run(single.level.String(), func(t *testing.T) {
+			if got, want := severity(single.level), single.want; got != want {
+				t.Errorf("GOT: %v; WANT: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestWriterUDP(t *testing.T) {
+	setNow(t)
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	w := dialTest(t, "udp", pc.LocalAddr().String())
+	log := gologs.New(w).SetInfo()
+
+	t.Run("rfc5424", func(t *testing.T) {
+		log.Warning().String("module", "server").Msg("slow request")
+		ensureString(t, readPacket(t, pc), `<12>1 2022-08-06T15:14:04.123456Z host app 42 - - {"level":"warning","module":"server","message":"slow request"}`)
+	})
+
+	t.Run("rfc3164", func(t *testing.T) {
+		w.SetFormat(RFC3164).SetFacility(Local3)
+		log.Error().Msg("cannot open")
+		ensureString(t, readPacket(t, pc), `<155>Aug  6 15:14:04 host app[42]: {"level":"error","message":"cannot open"}`)
+	})
+
+	t.Run("without level", func(t *testing.T) {
+		w.SetFormat(RFC5424).SetFacility(Daemon).SetHostname("").SetAppName("")
+		log.Log().Msg("starting")
+		ensureString(t, readPacket(t, pc), `<29>1 2022-08-06T15:14:04.123456Z - - 42 - - {"message":"starting"}`)
+	})
+}
+
+func TestWriterTCP(t *testing.T) {
+	setNow(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	w := dialTest(t, "tcp", ln.Addr().String())
+	log := gologs.New(w).SetInfo()
+	conn := <-accepted
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	// RFC 5424 messages are prefixed by their length, and RFC 3164 messages
+	// are followed by a newline.
+	log.Info().Msg("one")
+	log.Info().Msg("two")
+	w.SetFormat(RFC3164)
+	log.Info().Msg("three")
+
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	want := `82 <14>1 2022-08-06T15:14:04.123456Z host app 42 - - {"level":"info","message":"one"}` +
+		`82 <14>1 2022-08-06T15:14:04.123456Z host app 42 - - {"level":"info","message":"two"}` +
+		`<14>Aug  6 15:14:04 host app[42]: {"level":"info","message":"three"}` + "\n"
+	buf := make([]byte, len(want))
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatal(err)
+	}
+	ensureString(t, string(buf), want)
+
+	t.Run("reconnects", func(t *testing.T) {
+		_ = conn.Close()
+
+		// The first write after the collector closes the connection might
+		// succeed, so keep writing until the Writer reconnects.
+		var conn2 net.Conn
+		deadline := time.After(5 * time.Second)
+		for conn2 == nil {
+			log.Info().Msg("again")
+			select {
+			case conn2 = <-accepted:
+			case <-deadline:
+				t.Fatal("GOT: no connection; WANT: connection")
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		defer conn2.Close()
+
+		if err := conn2.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		line, err := bufio.NewReader(conn2).ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		ensureString(t, line, `<14>Aug  6 15:14:04 host app[42]: {"level":"info","message":"again"}`+"\n")
+	})
+}
+
+func TestWriterUnixgram(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unixgram sockets are not supported")
+	}
+	setNow(t)
+
+	// Socket pathnames are limited in length, so avoid a deeply nested
+	// temporary directory.
+	dir, err := os.MkdirTemp("", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pathname := filepath.Join(dir, "log")
+
+	pc, err := net.ListenPacket("unixgram", pathname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	w := dialTest(t, "unixgram", pathname).SetFormat(RFC3164)
+	if _, err := w.WriteLevel(gologs.Debug, []byte("plain text\n")); err != nil {
+		t.Fatal(err)
+	}
+	ensureString(t, readPacket(t, pc), `<15>Aug  6 15:14:04 host app[42]: plain text`)
+}
@@ -5,6 +5,10 @@ import (
 	"time"
 )
 
+// TimeFormatter appends the time property of an event to buf as a JSON
+// property name and value followed by a comma. A property named "time" is
+// renamed when the time key of the Logger has been changed using
+// Logger.SetTimeKey.
 type TimeFormatter func([]byte) []byte
 
 // TimeFormat returns a time formatter that appends the current time to buf as
@@ -21,6 +21,14 @@ func (w *Writer) SetLevel(level Level) *Writer {
 	return w
 }
 
+// SetTrace changes the Writer's level to Trace, which causes all writes to
+// the Writer to be logged to the underlying Logger with a level of Trace. The
+// change is made without blocking.
+func (w *Writer) SetTrace() *Writer {
+	atomic.StoreUint32((*uint32)(&w.level), uint32(Trace))
+	return w
+}
+
 // SetDebug changes the Writer's level to Debug, which causes all writes to
 // the Writer to be logged to the underlying Logger with a level of Debug. The
 // change is made without blocking.
@@ -72,24 +80,11 @@ func (w *Writer) SetError() *Writer {
 // io.Writer along with the write error.
 func (w *Writer) Write(buf []byte) (int, error) {
 	if Level(atomic.LoadUint32((*uint32)(&w.level))) > w.emitLevel {
+		w.event.counters.gate(w.emitLevel)
 		return len(buf), nil
 	}
 
-	var e *Event
-
-	switch w.emitLevel {
-	case Debug:
-		e = w.event.debug(w.branch)
-	case Verbose:
-		e = w.event.verbose(w.branch)
-	case Info:
-		e = w.event.info(w.branch)
-	case Warning:
-		e = w.event.warning(w.branch)
-	default:
-		e = w.event.error(w.branch)
-	}
-	if err := e.Msg(string(buf)); err != nil {
+	if err := w.event.begin(w.emitLevel, w.branch).Msg(string(buf)); err != nil {
 		return 0, err
 	}
 	return len(buf), nil